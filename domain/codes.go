@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+//code systems a search can filter by at any level of their hierarchy
+const (
+	CodeSystemSoc   = "soc"
+	CodeSystemNaics = "naics"
+)
+
+//IsCodeSystem tells if system is one of the code systems
+func IsCodeSystem(system string) bool {
+	return system == CodeSystemSoc || system == CodeSystemNaics
+}
+
+//Code names one SOC occupation or NAICS industry at some level of its hierarchy
+type Code struct {
+	Code  string
+	Title string
+	Level string
+}
+
+//CodePrefixes are the leading digits every code under code starts with.
+//SOC groups end in zeros (15-0000 major, 15-1200 minor, 15-1250 broad), NAICS codes
+//are their own prefix, sectors spanning several numbers (31-33) give one prefix each.
+//False when code is not a code of system
+func CodePrefixes(system string, code string) ([]string, bool) {
+	code = strings.TrimSpace(code)
+	switch system {
+	case CodeSystemSoc:
+		digits := socDigits(code)
+		if len(digits) < 2 || len(digits) > 6 || !isDigits(digits) {
+			return nil, false
+		}
+		if prefixes, ok := socSpans[digits]; ok {
+			return prefixes, true
+		}
+		prefix := strings.TrimRight(digits, "0")
+		if len(prefix) < 2 {
+			prefix = digits[:2]
+		}
+		return []string{prefix}, true
+
+	case CodeSystemNaics:
+		if from, to, ok := naicsRange(code); ok {
+			var prefixes []string
+			for sector := from; sector <= to; sector++ {
+				prefixes = append(prefixes, strconv.Itoa(sector))
+			}
+			return prefixes, true
+		}
+		if len(code) < 2 || len(code) > 6 || !isDigits(code) {
+			return nil, false
+		}
+		return []string{code}, true
+	}
+	return nil, false
+}
+
+//socSpans are the SOC broad groups whose detailed codes run past their own digits,
+//Physicians 29-1210 holds 29-1211 to 29-1229
+var socSpans = map[string][]string{
+	"291210": {"29121", "29122"},
+}
+
+//CodeLevel names the level of the hierarchy code sits at
+func CodeLevel(system string, code string) string {
+	prefixes, ok := CodePrefixes(system, code)
+	if !ok {
+		return ""
+	}
+	if system == CodeSystemSoc {
+		switch len(prefixes[0]) {
+		case 2:
+			return "major"
+		case 3, 4:
+			return "minor"
+		case 5:
+			return "broad"
+		}
+		return "detailed"
+	}
+	switch len(prefixes[0]) {
+	case 2:
+		return "sector"
+	case 3:
+		return "subsector"
+	case 4:
+		return "industry group"
+	case 5:
+		return "industry"
+	}
+	return "national industry"
+}
+
+//CaseCode is the code of system a case was filed under, in digits only
+func (lca Lca) CaseCode(system string) string {
+	if system == CodeSystemSoc {
+		return socDigits(lca.Soc_code)
+	}
+	return strings.TrimSpace(lca.Naics_code)
+}
+
+//HasCode tells if the case was filed under a code starting with any of prefixes
+func (lca Lca) HasCode(system string, prefixes []string) bool {
+	code := lca.CaseCode(system)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(code, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+//socDigits drops the dash and the O*NET suffix of a SOC code, 15-1132.00 is 151132
+func socDigits(code string) string {
+	code = strings.TrimSpace(code)
+	if i := strings.Index(code, "."); i >= 0 {
+		code = code[:i]
+	}
+	return strings.Replace(code, "-", "", 1)
+}
+
+//naicsRange reads a sector spanning several numbers like 31-33
+func naicsRange(code string) (int, int, bool) {
+	parts := strings.Split(code, "-")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 || !isDigits(parts[0]) || !isDigits(parts[1]) {
+		return 0, 0, false
+	}
+	from, _ := strconv.Atoi(parts[0])
+	to, _ := strconv.Atoi(parts[1])
+	return from, to, from >= 10 && from <= to
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
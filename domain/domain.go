@@ -1,10 +1,19 @@
 package domain
 
 import (
+	"errors"
+	"hash/fnv"
+	"strconv"
 	"strings"
 	"time"
 )
 
+//ErrInvalidCursor is returned when a page cursor does not belong to the search it is used with
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+//ErrNotFound is returned when an id does not name anything in the store
+var ErrNotFound = errors.New("not found")
+
 // Lca info
 type Lca struct {
 	Year                int
@@ -19,6 +28,7 @@ type Lca struct {
 	Employer_city       string
 	Employer_state      string
 	Employer_zip        string
+	Employer_fein       string
 	Job_title           string
 	Soc_code            string
 	Soc_name            string
@@ -34,12 +44,203 @@ type Lca struct {
 	Work_location_city  string
 	Work_location_state string
 	Work_location_zip   string
+	Visa_class          string
 }
 
 //LcaRepo handles read/write to database
 type LcaRepo interface {
-	Get(searchCriteria SearchCriteria) ([]Lca, error)
+	Get(searchCriteria SearchCriteria) (LcaIterator, error)
 	GetEmployerNames(has string) map[string]int
+	GetJobTitles(has string, employer string, zipcode string) []TitleCount
+	GetPayStats(searchCriteria SearchCriteria, groupBy string) ([]PayStats, error)
+	GetEmployerProfile(id string) (EmployerProfile, error)
+	GetTimeSeries(searchCriteria SearchCriteria, interval string, dateField string) ([]TimeBucket, error)
+	CompareEmployers(ids []string, searchCriteria SearchCriteria) ([]EmployerComparison, error)
+	EvaluateOffer(offer Offer) (OfferEvaluation, error)
+	GetCodes(system string, under string, has string) ([]Code, error)
+	GetZipcodePoint(zipcode string) (GeoPoint, bool)
+}
+
+//GeoPoint is the centroid of a zipcode, in degrees
+type GeoPoint struct {
+	Lat  float64
+	Long float64
+}
+
+//Offer to rank among comparable cases, those with the same normalized job title or
+//SOC code, within Radius miles of Zipcode and in the latest Years of data, years counted by YearMode
+type Offer struct {
+	JobTitle string
+	SocCode  string
+	Pay      int
+	Zipcode  string
+	Radius   int
+	Years    int
+	YearMode string
+}
+
+//OfferEvaluation is where an offer ranks among comparable cases. WageLevel is the DOL level
+//an offer at this percentile of the cohort would meet, levels I to IV sit at about
+//the 17th, 34th, 50th and 67th percentiles
+type OfferEvaluation struct {
+	Pay         int
+	Percentile  float64
+	CohortSize  int
+	WageLevel   string
+	Cohort      PayStats
+	Comparables []Lca
+	YearMode    string
+}
+
+//EmployerComparison is one employer's numbers for the same role and location filters.
+//Every comparison of a request lists the same wage levels in the same order.
+//Dependency flags come from the employer's latest filing
+type EmployerComparison struct {
+	Id              string
+	Name            string
+	Filings         int
+	Workers         int
+	CertifiedRate   float64
+	H1bDependent    bool
+	WillfulViolator bool
+	Levels          []LevelPay
+}
+
+//LevelPay is the filings and median yearly pay at one wage level
+type LevelPay struct {
+	Level     string
+	Filings   int
+	MedianPay int
+}
+
+//intervals a time series can be bucketed by
+const (
+	IntervalMonth   = "month"
+	IntervalQuarter = "quarter"
+)
+
+//IsDateField tells if key is a date sort key a time series can be bucketed on
+func IsDateField(key string) bool {
+	switch key {
+	case SortBySubmitDate, SortByDecisionDate, SortByStartDate:
+		return true
+	}
+	return false
+}
+
+//TimeBucket counts the filings and workers of one month (2019-03) or quarter (2019-Q1)
+type TimeBucket struct {
+	Period  string
+	Start   time.Time
+	Filings int
+	Workers int
+}
+
+//EmployerID is a short url safe id, stable across loads, for an employer name
+func EmployerID(name string) string {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+//EmployerProfile consolidates every filing of one employer.
+//Withdrawn counts both WITHDRAWN and CERTIFIED-WITHDRAWN cases
+type EmployerProfile struct {
+	Id            string
+	Name          string
+	Filings       int
+	Workers       int
+	CertifiedRate float64
+	DeniedRate    float64
+	WithdrawnRate float64
+	FirstFiled    time.Time
+	LastFiled     time.Time
+	Years         []EmployerYear
+	TopTitles     []TitlePay
+	WorksiteZips  []FacetCount
+	VisaClasses   []FacetCount
+	Fein          string
+	Perm          PermHistory
+	Petitions     Petitions
+}
+
+//EmployerYear is an employer's filings in one disclosure year, flagged when any filing that year was
+type EmployerYear struct {
+	Year            int
+	Filings         int
+	Workers         int
+	H1bDependent    bool
+	WillfulViolator bool
+}
+
+//TitlePay is how often a job title was filed and its median yearly pay
+type TitlePay struct {
+	Title     string
+	Filings   int
+	MedianPay int
+}
+
+//PayStats summarizes Pay of the matching cases in one group
+type PayStats struct {
+	Group string
+	Count int
+	Mean  int
+	P10   int
+	P25   int
+	P50   int
+	P75   int
+	P90   int
+}
+
+//IsGroupBy tells if name is a facet pay statistics can be grouped by
+func IsGroupBy(name string) bool {
+	switch name {
+	case FacetYear, FacetWageLevel, FacetEmployer, FacetSoc, FacetVisaClass:
+		return true
+	}
+	return false
+}
+
+//TitleCount is a job title and the number of cases filed with it
+type TitleCount struct {
+	Title string
+	Count int
+}
+
+//LcaIterator walks one page of search results in a stable order
+type LcaIterator interface {
+	Next() bool
+	Lca() Lca
+	Total() int
+	PageSize() int
+	NextCursor() string
+	Facets() map[string][]FacetCount
+}
+
+//FacetCount is how many matching cases share one value of a facet
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+//facets that can be counted over a search through SearchCriteria.Facets
+const (
+	FacetEmployer  = "employer"
+	FacetYear      = "year"
+	FacetWageLevel = "level"
+	FacetState     = "state"
+	FacetStatus    = "status"
+	FacetSoc       = "soc"
+	FacetVisaClass = "visa"
+)
+
+//IsFacet tells if name is one of the facets
+func IsFacet(name string) bool {
+	switch name {
+	case FacetEmployer, FacetYear, FacetWageLevel, FacetState, FacetStatus, FacetSoc, FacetVisaClass:
+		return true
+	}
+	return false
 }
 
 //SearchCriteria for search
@@ -50,14 +251,154 @@ type SearchCriteria struct {
 	PayMin             int
 	PayMax             int
 	ExcludeH1Dependent bool
+	ExcludeWillful     bool
+	State              string
 	H1Year             int
 	JobTitle           string
+	VisaClasses        []string
+	SocCodes           []string
+	NaicsCodes         []string
+	Query              Filter
+	PageSize           int
+	Cursor             string
+	SortBy             string
+	SortDescending     bool
+	Facets             []string
+	CaseStatuses       []string
+	AnyCaseStatus      bool
+	WageLevels         []string
+	FullTimeOnly       bool
+	MinWorkers         int
+	MaxWorkers         int
+	SubmitDates        DateRange
+	DecisionDates      DateRange
+	StartDates         DateRange
+	EndDates           DateRange
+	YearMode           string
+}
+
+//year modes for SearchCriteria.YearMode, telling which year H1Year and the year facet compare with
+const (
+	YearModeFiscal = "fiscal" //DOL fiscal year of the disclosure file, as published in DOL totals
+	YearModeFiling = "filing" //calendar year the case was submitted
+	YearModeStart  = "start"  //calendar year employment starts, the default
+)
+
+//IsYearMode tells if mode is one of the year modes
+func IsYearMode(mode string) bool {
+	switch mode {
+	case YearModeFiscal, YearModeFiling, YearModeStart:
+		return true
+	}
+	return false
+}
+
+//DateRange includes both ends, a zero From or To leaves that end open
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+//IsZero tells if the range is open at both ends
+func (r DateRange) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+//Contains tells if t falls in the range, a missing date is in no range
+func (r DateRange) Contains(t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+	return (r.From.IsZero() || !t.Before(r.From)) && (r.To.IsZero() || !t.After(r.To))
+}
+
+//case statuses of DOL disclosure files. Searches only return CERTIFIED cases unless SearchCriteria
+//asks for other CaseStatuses or AnyCaseStatus, or its Query has a status term
+const (
+	StatusCertified          = "CERTIFIED"
+	StatusCertifiedWithdrawn = "CERTIFIED-WITHDRAWN"
+	StatusDenied             = "DENIED"
+	StatusWithdrawn          = "WITHDRAWN"
+)
+
+//IsCaseStatus tells if status is one of the DOL case statuses of LCA or PERM cases
+func IsCaseStatus(status string) bool {
+	switch status {
+	case StatusCertified, StatusCertifiedWithdrawn, StatusDenied, StatusWithdrawn, StatusCertifiedExpired:
+		return true
+	}
+	return false
+}
+
+//visa classes of DOL LCA disclosure files, as DOL spells them
+const (
+	VisaH1b           = "H-1B"
+	VisaH1b1Chile     = "H-1B1 Chile"
+	VisaH1b1Singapore = "H-1B1 Singapore"
+	VisaE3            = "E-3 Australian"
+)
+
+//NormalizeVisaClass turns spellings like "h1b1 chile" or "E3" into the DOL visa class, empty when unknown
+func NormalizeVisaClass(visaClass string) string {
+	switch strings.NewReplacer("-", "", " ", "").Replace(strings.ToUpper(visaClass)) {
+	case "H1B":
+		return VisaH1b
+	case "H1B1CHILE":
+		return VisaH1b1Chile
+	case "H1B1SINGAPORE":
+		return VisaH1b1Singapore
+	case "E3", "E3AUSTRALIAN", "E3AUSTRALIA":
+		return VisaE3
+	}
+	return ""
+}
+
+//sort keys for SearchCriteria.SortBy, results are ordered by case number when empty
+const (
+	SortByPay          = "pay"
+	SortBySubmitDate   = "submitted"
+	SortByDecisionDate = "decided"
+	SortByStartDate    = "start"
+	SortByDistance     = "distance"
+	SortByEmployer     = "employer"
+	SortByWorkers      = "workers"
+)
+
+//IsSortKey tells if key is one of the SortBy keys
+func IsSortKey(key string) bool {
+	switch key {
+	case SortByPay, SortBySubmitDate, SortByDecisionDate, SortByStartDate, SortByDistance, SortByEmployer, SortByWorkers:
+		return true
+	}
+	return false
 }
 
 func (lca Lca) PayBetween(min int, max int) bool {
 	return min <= lca.Pay && lca.Pay <= max
 }
 
+//YearIn gives the case's year for a year mode, start year when the mode is empty
+func (lca Lca) YearIn(mode string) int {
+	switch mode {
+	case YearModeFiscal:
+		return lca.Year
+	case YearModeFiling:
+		return lca.Submit_date.Year()
+	}
+	return lca.Start_date.Year()
+}
+
+//VisaClass of the case, H-1B for files from before visa classes were loaded
+func (lca Lca) VisaClass() string {
+	if visaClass := NormalizeVisaClass(lca.Visa_class); len(visaClass) > 0 {
+		return visaClass
+	}
+	if len(lca.Visa_class) > 0 {
+		return lca.Visa_class
+	}
+	return VisaH1b
+}
+
 func (lca Lca) H1FiledAfter(after time.Time) bool {
 	return lca.Submit_date.After(after)
 }
@@ -69,3 +410,85 @@ func (lca Lca) EmployerNamed(employer string) bool {
 func (lca Lca) HasJobTitle(jobTile string) bool {
 	return strings.Contains(lca.Job_title, jobTile)
 }
+
+//NormalizeWageLevel turns the wage level spellings of different disclosure years ("Level II", "II", "2") into I to IV
+func NormalizeWageLevel(level string) string {
+	level = strings.TrimSpace(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(level)), "LEVEL"))
+	switch level {
+	case "1":
+		return "I"
+	case "2":
+		return "II"
+	case "3":
+		return "III"
+	case "4":
+		return "IV"
+	}
+	return level
+}
+
+var titleAbbreviations = map[string]string{
+	"SR":    "SENIOR",
+	"SNR":   "SENIOR",
+	"JR":    "JUNIOR",
+	"ENG":   "ENGINEER",
+	"ENGR":  "ENGINEER",
+	"SW":    "SOFTWARE",
+	"DEV":   "DEVELOPER",
+	"MGR":   "MANAGER",
+	"MGMT":  "MANAGEMENT",
+	"ASSOC": "ASSOCIATE",
+	"ASST":  "ASSISTANT",
+	"SYS":   "SYSTEMS",
+	"ADMIN": "ADMINISTRATOR",
+	"PROG":  "PROGRAMMER",
+	"ARCH":  "ARCHITECT",
+	"SPEC":  "SPECIALIST",
+	"DIR":   "DIRECTOR",
+	"VP":    "VICE PRESIDENT",
+}
+
+//NormalizeTitle upper cases a job title, drops punctuation and spells out common abbreviations,
+//so "Sr. Software Engr" and "SENIOR SOFTWARE ENGINEER" compare equal
+func NormalizeTitle(title string) string {
+	words := strings.FieldsFunc(strings.ToUpper(title), func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	for i, word := range words {
+		if full, ok := titleAbbreviations[word]; ok {
+			words[i] = full
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+//employerNameNoise are words employers spell differently across filings, or leave out
+var employerNameNoise = map[string]bool{
+	"THE": true, "AND": true, "INC": true, "INCORPORATED": true, "LLC": true, "LLP": true, "LP": true, "PLLC": true,
+	"PC": true, "CORP": true, "CORPORATION": true, "CO": true, "COMPANY": true, "LTD": true, "LIMITED": true,
+}
+
+//NormalizeEmployerName upper cases an employer name, drops punctuation and the words that vary
+//between filings, so "Acme, Inc." and "ACME INC" compare equal
+func NormalizeEmployerName(name string) string {
+	words := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	kept := words[:0]
+	for _, word := range words {
+		if !employerNameNoise[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+//NormalizeFein keeps the digits of a federal employer identification number, 12-3456789 is 123456789
+func NormalizeFein(fein string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, fein)
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"time"
+)
+
+//StatusCertifiedExpired is a PERM certification the employer did not file an I-140 with in time,
+//PERM searches return it along with CERTIFIED by default
+const StatusCertifiedExpired = "CERTIFIED-EXPIRED"
+
+// Perm info, one DOL PERM labor certification
+type Perm struct {
+	Year                   int
+	Case_number            string
+	Case_status            string
+	Received_date          time.Time
+	Decision_date          time.Time
+	Employer_name          string
+	Employer_address       string
+	Employer_city          string
+	Employer_state         string
+	Employer_zip           string
+	Employer_fein          string
+	Job_title              string
+	Soc_code               string
+	Soc_name               string
+	Naics_code             string
+	Wage_offer_from        string
+	Wage_offer_to          string
+	Wage_unit              string
+	Pay                    int
+	Pw_level               string
+	Class_of_admission     string
+	Country_of_citizenship string
+	Work_location_city     string
+	Work_location_state    string
+	Work_location_zip      string
+}
+
+//PermRepo handles reads of PERM cases, searched with the same criteria as LCAs
+type PermRepo interface {
+	Get(searchCriteria SearchCriteria) (PermIterator, error)
+	GetPayStats(searchCriteria SearchCriteria, groupBy string) ([]PayStats, error)
+	GetEmployerHistory(name string, fein string) PermHistory
+}
+
+//PermHistory is an employer's PERM filings, found by FEIN or, when either side has no FEIN,
+//by normalized name. Both CERTIFIED and CERTIFIED-EXPIRED count as certified, Occupations
+//are the most filed SOC titles
+type PermHistory struct {
+	Filings       int
+	CertifiedRate float64
+	Occupations   []FacetCount
+}
+
+//PermIterator walks one page of PERM search results in a stable order
+type PermIterator interface {
+	Next() bool
+	Perm() Perm
+	Total() int
+	PageSize() int
+	NextCursor() string
+	Facets() map[string][]FacetCount
+}
+
+//AsLca views a PERM case through the LCA fields SearchCriteria, queries and facets look at.
+//The received date stands in for the submit date, the prevailing wage level for the wage level
+//and the class of admission, the visa the worker holds, for the visa class. PERM jobs are
+//permanent full time jobs for one worker, and have no start date to count years by
+func (perm Perm) AsLca() Lca {
+	return Lca{
+		Year:                perm.Year,
+		Case_number:         perm.Case_number,
+		Case_status:         perm.Case_status,
+		Submit_date:         perm.Received_date,
+		Decision_date:       perm.Decision_date,
+		Employer_name:       perm.Employer_name,
+		Employer_address:    perm.Employer_address,
+		Employer_city:       perm.Employer_city,
+		Employer_state:      perm.Employer_state,
+		Employer_zip:        perm.Employer_zip,
+		Job_title:           perm.Job_title,
+		Soc_code:            perm.Soc_code,
+		Soc_name:            perm.Soc_name,
+		Naics_code:          perm.Naics_code,
+		Total_workers:       1,
+		Full_time:           "Y",
+		Wage_rate:           perm.Wage_offer_from,
+		Wage_unit:           perm.Wage_unit,
+		Wage_level:          perm.Pw_level,
+		Pay:                 perm.Pay,
+		Work_location_city:  perm.Work_location_city,
+		Work_location_state: perm.Work_location_state,
+		Work_location_zip:   perm.Work_location_zip,
+		Visa_class:          perm.Class_of_admission,
+	}
+}
+
+//UscisRepo handles reads of the USCIS H-1B Employer Data Hub petition counts
+type UscisRepo interface {
+	GetEmployerPetitions(name string, fein string) Petitions
+}
+
+//Petitions are the H-1B petitions USCIS decided for an employer, initial ones for new
+//employment and continuing ones for extensions and amendments, in total and by fiscal year
+type Petitions struct {
+	InitialApprovals    int
+	InitialDenials      int
+	ContinuingApprovals int
+	ContinuingDenials   int
+	Years               []PetitionYear
+}
+
+//PetitionYear is one fiscal year of an employer's USCIS petition decisions
+type PetitionYear struct {
+	Year                int
+	InitialApprovals    int
+	InitialDenials      int
+	ContinuingApprovals int
+	ContinuingDenials   int
+}
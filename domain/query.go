@@ -0,0 +1,400 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//Filter is a compiled /lca query, evaluated by the store against each candidate case
+type Filter interface {
+	Match(lca Lca) bool
+}
+
+//QueryError tells where and why a query could not be parsed
+type QueryError struct {
+	Pos int
+	Msg string
+}
+
+func (err *QueryError) Error() string {
+	return fmt.Sprintf("query error at position %d: %s", err.Pos+1, err.Msg)
+}
+
+type andFilter struct {
+	left, right Filter
+}
+
+func (f andFilter) Match(lca Lca) bool {
+	return f.left.Match(lca) && f.right.Match(lca)
+}
+
+type orFilter struct {
+	left, right Filter
+}
+
+func (f orFilter) Match(lca Lca) bool {
+	return f.left.Match(lca) || f.right.Match(lca)
+}
+
+type notFilter struct {
+	filter Filter
+}
+
+func (f notFilter) Match(lca Lca) bool {
+	return !f.filter.Match(lca)
+}
+
+type textFilter struct {
+	name  string
+	value string
+	match func(lca Lca, value string) bool
+}
+
+func (f textFilter) Match(lca Lca) bool {
+	return f.match(lca, f.value)
+}
+
+type numberFilter struct {
+	name     string
+	op       string
+	value    int
+	field    func(lca Lca, yearMode string) int
+	yearMode string
+}
+
+func (f numberFilter) Match(lca Lca) bool {
+	n := f.field(lca, f.yearMode)
+	switch f.op {
+	case ">":
+		return n > f.value
+	case ">=":
+		return n >= f.value
+	case "<":
+		return n < f.value
+	case "<=":
+		return n <= f.value
+	}
+	return n == f.value
+}
+
+//QueryUses tells if a query has a term on a field anywhere, negated or not
+func QueryUses(filter Filter, field string) bool {
+	switch f := filter.(type) {
+	case andFilter:
+		return QueryUses(f.left, field) || QueryUses(f.right, field)
+	case orFilter:
+		return QueryUses(f.left, field) || QueryUses(f.right, field)
+	case notFilter:
+		return QueryUses(f.filter, field)
+	case textFilter:
+		return f.name == field
+	case numberFilter:
+		return f.name == field
+	}
+	return false
+}
+
+func equalsUpper(s string, value string) bool {
+	return strings.ToUpper(s) == value
+}
+
+func containsUpper(s string, value string) bool {
+	return strings.Contains(strings.ToUpper(s), value)
+}
+
+//hasCode matches any level of the hierarchy, values that are not codes match as plain prefixes
+func hasCode(lca Lca, system string, value string) bool {
+	if prefixes, ok := CodePrefixes(system, value); ok {
+		return lca.HasCode(system, prefixes)
+	}
+	return strings.HasPrefix(lca.CaseCode(system), value)
+}
+
+// textFields match with ':' or '='; values are upper cased before matching
+var textFields = map[string]func(lca Lca, value string) bool{
+	"title": func(lca Lca, value string) bool {
+		return containsUpper(lca.Job_title, value)
+	},
+	"employer": func(lca Lca, value string) bool {
+		return containsUpper(lca.Employer_name, value)
+	},
+	"status": func(lca Lca, value string) bool {
+		return equalsUpper(lca.Case_status, value)
+	},
+	"state": func(lca Lca, value string) bool {
+		return equalsUpper(lca.Employer_state, value) || equalsUpper(lca.Work_location_state, value)
+	},
+	"city": func(lca Lca, value string) bool {
+		return equalsUpper(lca.Employer_city, value) || equalsUpper(lca.Work_location_city, value)
+	},
+	"zip": func(lca Lca, value string) bool {
+		return lca.Employer_zip == "1"+fmt.Sprintf("%05s", value)
+	},
+	"soc": func(lca Lca, value string) bool {
+		return hasCode(lca, CodeSystemSoc, value)
+	},
+	"naics": func(lca Lca, value string) bool {
+		return hasCode(lca, CodeSystemNaics, value)
+	},
+	"level": func(lca Lca, value string) bool {
+		return NormalizeWageLevel(lca.Wage_level) == NormalizeWageLevel(value)
+	},
+	"dependent": func(lca Lca, value string) bool {
+		return equalsUpper(lca.H1b_dependent, value)
+	},
+	"willful": func(lca Lca, value string) bool {
+		return equalsUpper(lca.Willful_voilator, value)
+	},
+	"fulltime": func(lca Lca, value string) bool {
+		return equalsUpper(lca.Full_time, value)
+	},
+	"visa": func(lca Lca, value string) bool {
+		return lca.VisaClass() == NormalizeVisaClass(value)
+	},
+}
+
+// numberFields also accept the comparison operators >, >=, < and <=, year counts by the year mode
+var numberFields = map[string]func(lca Lca, yearMode string) int{
+	"pay": func(lca Lca, yearMode string) int {
+		return lca.Pay
+	},
+	"workers": func(lca Lca, yearMode string) int {
+		return lca.Total_workers
+	},
+	"year": func(lca Lca, yearMode string) int {
+		return lca.YearIn(yearMode)
+	},
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenize(q string) ([]token, error) {
+	var tokens []token
+	runes := []rune(q)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == ':' || r == '=':
+			tokens = append(tokens, token{kind: tokenOp, text: string(r), pos: i})
+			i++
+		case r == '>' || r == '<':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+			i += len(op)
+		case r == '"':
+			start := i
+			i++
+			var sb strings.Builder
+			for i < len(runes) && runes[i] != '"' {
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i == len(runes) {
+				return nil, &QueryError{Pos: start, Msg: "unterminated quoted value"}
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: start})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("():=<>\"", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), pos: start})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+type queryParser struct {
+	tokens   []token
+	i        int
+	yearMode string
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *queryParser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *queryParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.ToUpper(t.text) == keyword
+}
+
+// or := and ("OR" and)*
+func (p *queryParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+// and := not (["AND"] not)*, adjacent terms are and-ed together
+func (p *queryParser) parseAnd() (Filter, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind == tokenEOF || t.kind == tokenRParen || p.isKeyword("OR") {
+			return left, nil
+		}
+		if p.isKeyword("AND") {
+			p.next()
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter{left: left, right: right}
+	}
+}
+
+// not := "NOT" not | "(" or ")" | term
+func (p *queryParser) parseNot() (Filter, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		filter, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notFilter{filter: filter}, nil
+	}
+
+	t := p.peek()
+	if t.kind == tokenLParen {
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &QueryError{Pos: closing.pos, Msg: "missing closing parenthesis for the one at position " + strconv.Itoa(t.pos+1)}
+		}
+		return filter, nil
+	}
+
+	return p.parseTerm()
+}
+
+// term := field op value
+func (p *queryParser) parseTerm() (Filter, error) {
+	field := p.next()
+	switch field.kind {
+	case tokenEOF:
+		return nil, &QueryError{Pos: field.pos, Msg: "unexpected end of query, expected field:value"}
+	case tokenWord:
+	default:
+		return nil, &QueryError{Pos: field.pos, Msg: fmt.Sprintf("unexpected %q, expected a field name", field.text)}
+	}
+
+	name := strings.ToLower(field.text)
+	op := p.next()
+	if op.kind != tokenOp {
+		return nil, &QueryError{Pos: op.pos, Msg: fmt.Sprintf("expected an operator after %q", field.text)}
+	}
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, &QueryError{Pos: value.pos, Msg: fmt.Sprintf("expected a value after %s%s", field.text, op.text)}
+	}
+
+	if match, ok := textFields[name]; ok {
+		if op.text != ":" && op.text != "=" {
+			return nil, &QueryError{Pos: op.pos, Msg: fmt.Sprintf("operator %s is not allowed on %s", op.text, name)}
+		}
+		return textFilter{name: name, value: strings.ToUpper(strings.TrimSpace(value.text)), match: match}, nil
+	}
+
+	if fieldValue, ok := numberFields[name]; ok {
+		n, err := parseQueryNumber(value.text)
+		if err != nil {
+			return nil, &QueryError{Pos: value.pos, Msg: fmt.Sprintf("%s needs a number, got %q", name, value.text)}
+		}
+		return numberFilter{name: name, op: op.text, value: n, field: fieldValue, yearMode: p.yearMode}, nil
+	}
+
+	return nil, &QueryError{Pos: field.pos, Msg: fmt.Sprintf("unknown field %q", field.text)}
+}
+
+// parseQueryNumber reads whole numbers, allowing thousands separators and a k suffix (180k)
+func parseQueryNumber(s string) (int, error) {
+	s = strings.Replace(strings.TrimPrefix(s, "$"), ",", "", -1)
+	multiplier := 1
+	if strings.HasSuffix(s, "k") || strings.HasSuffix(s, "K") {
+		multiplier = 1000
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.Atoi(s)
+	return n * multiplier, err
+}
+
+//ParseQuery compiles a boolean query such as
+//	title:"data scientist" AND (employer:GOOGLE OR employer:META) AND pay>=180000 AND NOT status:DENIED
+//into a Filter, year:2020 counting years by yearMode. An empty query returns a nil Filter
+func ParseQuery(q string, yearMode string) (Filter, error) {
+	tokens, err := tokenize(q)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, nil
+	}
+
+	p := &queryParser{tokens: tokens, yearMode: yearMode}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, &QueryError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+	}
+	return filter, nil
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	filter, err := ParseQuery(`title:"data scientist" AND (employer:GOOGLE OR employer:META) AND pay>=180000 AND NOT status:DENIED`, "")
+	if err != nil {
+		t.Fatalf("got %v; want no error", err)
+	}
+
+	lca := Lca{Job_title: "SENIOR DATA SCIENTIST", Employer_name: "META PLATFORMS, INC.", Pay: 185000, Case_status: "CERTIFIED"}
+	if !filter.Match(lca) {
+		t.Errorf("got no match; want %v to match", lca)
+	}
+
+	lca.Case_status = "DENIED"
+	if filter.Match(lca) {
+		t.Errorf("got match; want denied case to be excluded")
+	}
+
+	lca.Case_status = "CERTIFIED"
+	lca.Employer_name = "AMAZON.COM SERVICES LLC"
+	if filter.Match(lca) {
+		t.Errorf("got match; want other employers to be excluded")
+	}
+
+	lca.Employer_name = "GOOGLE LLC"
+	lca.Pay = 179999
+	if filter.Match(lca) {
+		t.Errorf("got match; want pay below 180000 to be excluded")
+	}
+}
+
+func TestParseQueryImplicitAnd(t *testing.T) {
+	filter, err := ParseQuery(`state:wa pay>150k`, "")
+	if err != nil {
+		t.Fatalf("got %v; want no error", err)
+	}
+	if !filter.Match(Lca{Work_location_state: "WA", Pay: 150001}) {
+		t.Errorf("got no match; want terms to be and-ed")
+	}
+	if filter.Match(Lca{Work_location_state: "WA", Pay: 150000}) {
+		t.Errorf("got match; want pay>150k to be strict")
+	}
+}
+
+func TestParseQueryEmpty(t *testing.T) {
+	filter, err := ParseQuery("  ", "")
+	if filter != nil || err != nil {
+		t.Errorf("got %v, %v; want nil filter and no error", filter, err)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for q, pos := range map[string]int{
+		`title:"data`:             6,
+		`(employer:GOOGLE`:        16,
+		`salary>100`:              0,
+		`title>DATA`:              5,
+		`pay>=lots`:               5,
+		`employer:GOOGLE OR`:      18,
+		`employer:GOOGLE) pay>10`: 15,
+		`employer GOOGLE`:         9,
+	} {
+		_, err := ParseQuery(q, "")
+		queryErr, ok := err.(*QueryError)
+		if !ok {
+			t.Errorf("%s: got %v; want a QueryError", q, err)
+			continue
+		}
+		if queryErr.Pos != pos {
+			t.Errorf("%s: got error at %d (%s); want %d", q, queryErr.Pos, queryErr.Msg, pos)
+		}
+	}
+}
+
+func TestParseQueryCodes(t *testing.T) {
+	filter, err := ParseQuery(`soc:15-0000 naics:31-33`, "")
+	if err != nil {
+		t.Fatalf("got %v; want no error", err)
+	}
+	if !filter.Match(Lca{Soc_code: "15-1132.00", Naics_code: "334413"}) {
+		t.Errorf("got no match; want codes below the groups to match")
+	}
+	if filter.Match(Lca{Soc_code: "17-2071", Naics_code: "334413"}) {
+		t.Errorf("got match; want other major groups to be excluded")
+	}
+}
+
+func TestParseQueryYearMode(t *testing.T) {
+	lca := Lca{Year: 2021, Start_date: time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)}
+	for mode, want := range map[string]bool{YearModeStart: false, YearModeFiscal: true} {
+		filter, err := ParseQuery(`year:2021`, mode)
+		if err != nil || filter.Match(lca) != want {
+			t.Errorf("%s: got %v, %v; want match %v", mode, filter.Match(lca), err, want)
+		}
+	}
+}
+
+func TestQueryUses(t *testing.T) {
+	for q, want := range map[string]bool{
+		`status:DENIED`:                        true,
+		`title:engineer AND NOT status:DENIED`: true,
+		`(pay>100k OR status=WITHDRAWN)`:       true,
+		`title:status AND employer:ACME`:       false,
+		``:                                     false,
+	} {
+		filter, err := ParseQuery(q, "")
+		if err != nil || QueryUses(filter, "status") != want {
+			t.Errorf("%s: got %v, %v; want %v", q, QueryUses(filter, "status"), err, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	domain "github.com/kk3399/empnearme/domain"
+	logWriter "github.com/kk3399/empnearme/log"
+)
+
+//CodesHandler handles /codes/soc and /codes/naics requests listing and naming codes,
+//under=15-0000 keeps the codes below a group and has=ENGINEER those whose title has the text.
+//Codes come from the bundled code tables and the cases, those missing from the tables are untitled
+type CodesHandler struct {
+	LcaRepo domain.LcaRepo
+	Log     logWriter.Writer
+}
+
+func (codesHandler CodesHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	system, _ := shiftPath(req.URL.Path)
+	if !domain.IsCodeSystem(system) {
+		http.Error(res, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	p := req.URL.Query()
+	under := p.Get("under")
+	if _, ok := domain.CodePrefixes(system, under); len(under) > 0 && !ok {
+		http.Error(res, "under must be a "+system+" code", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := codesHandler.LcaRepo.GetCodes(system, under, p.Get("has"))
+	if err == domain.ErrNotFound {
+		http.Error(res, "Not Found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		codesHandler.Log.Write(err)
+		http.Error(res, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	json.NewEncoder(res).Encode(codes)
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	domain "github.com/kk3399/empnearme/domain"
+	logWriter "github.com/kk3399/empnearme/log"
+)
+
+const (
+	minCompareEmployers = 2
+	maxCompareEmployers = 5
+)
+
+//compareResponse is the numbers of each compared employer, years counted by YearMode
+type compareResponse struct {
+	Employers []domain.EmployerComparison
+	YearMode  string
+}
+
+//CompareHandler handles side by side employer requests, /compare?id=..&id=.. with /lca filters
+type CompareHandler struct {
+	LcaRepo domain.LcaRepo
+	Log     logWriter.Writer
+}
+
+func (compareHandler CompareHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	p := req.URL.Query()
+	filter, err := searchCriteria(p)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ids := p["id"]
+	if len(ids) < minCompareEmployers || len(ids) > maxCompareEmployers {
+		http.Error(res, "compare needs 2 to 5 employer ids", http.StatusBadRequest)
+		return
+	}
+
+	comparisons, err := compareHandler.LcaRepo.CompareEmployers(ids, filter)
+	if err == domain.ErrNotFound {
+		http.Error(res, "unknown employer id", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		compareHandler.Log.Write(err)
+		http.Error(res, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	json.NewEncoder(res).Encode(compareResponse{Employers: comparisons, YearMode: filter.YearMode})
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+const isoDateLayout = "2006-01-02"
+
+//yearMode reads ym, the year mode years are counted by, start year by default
+func yearMode(p url.Values) (string, error) {
+	mode := p.Get("ym")
+	if len(mode) == 0 {
+		return domain.YearModeStart, nil
+	}
+	if !domain.IsYearMode(mode) {
+		return "", errors.New("year mode must be fiscal, filing or start")
+	}
+	return mode, nil
+}
+
+//searchCriteria reads the filters shared by /lca and the aggregate endpoints
+func searchCriteria(p url.Values) (domain.SearchCriteria, error) {
+	zip := p.Get("z")
+	job := p.Get("j")
+	x, _ := strconv.Atoi(p.Get("x"))
+	radius, _ := strconv.Atoi(p.Get("r"))
+	emp := strings.ToUpper(p.Get("e"))
+	payMin, _ := strconv.Atoi(p.Get("ps"))
+	payMax, _ := strconv.Atoi(p.Get("pe"))
+	year, _ := strconv.Atoi(p.Get("y"))
+	ft, _ := strconv.Atoi(p.Get("ft"))
+	wv, _ := strconv.Atoi(p.Get("wv"))
+	minWorkers, _ := strconv.Atoi(p.Get("wmin"))
+	maxWorkers, _ := strconv.Atoi(p.Get("wmax"))
+
+	mode, err := yearMode(p)
+	if err != nil {
+		return domain.SearchCriteria{}, err
+	}
+
+	query, err := domain.ParseQuery(p.Get("q"), mode)
+	if err != nil {
+		return domain.SearchCriteria{}, err
+	}
+
+	filter := domain.SearchCriteria{Radius: radius, Zipcode: zip, Employer: emp, PayMin: payMin, PayMax: payMax, H1Year: year, JobTitle: job, Query: query,
+		MinWorkers: minWorkers, MaxWorkers: maxWorkers, State: p.Get("st")}
+	if x > 0 {
+		filter.ExcludeH1Dependent = true
+	}
+	if ft > 0 {
+		filter.FullTimeOnly = true
+	}
+	if wv > 0 {
+		filter.ExcludeWillful = true
+	}
+
+	for _, level := range multiValue(p, "wl") {
+		level = domain.NormalizeWageLevel(level)
+		if level != "I" && level != "II" && level != "III" && level != "IV" {
+			return filter, errors.New("wage level must be I, II, III or IV")
+		}
+		filter.WageLevels = append(filter.WageLevels, level)
+	}
+
+	for _, visaClass := range multiValue(p, "v") {
+		if len(domain.NormalizeVisaClass(visaClass)) == 0 {
+			return filter, errors.New("visa class must be H-1B, H-1B1 Chile, H-1B1 Singapore or E-3 Australian")
+		}
+		filter.VisaClasses = append(filter.VisaClasses, domain.NormalizeVisaClass(visaClass))
+	}
+
+	for _, code := range multiValue(p, "soc") {
+		if _, ok := domain.CodePrefixes(domain.CodeSystemSoc, code); !ok {
+			return filter, errors.New("soc must be a SOC code like 15-1252 or 15-0000")
+		}
+		filter.SocCodes = append(filter.SocCodes, code)
+	}
+	for _, code := range multiValue(p, "naics") {
+		if _, ok := domain.CodePrefixes(domain.CodeSystemNaics, code); !ok {
+			return filter, errors.New("naics must be a NAICS code like 541511, 54 or 31-33")
+		}
+		filter.NaicsCodes = append(filter.NaicsCodes, code)
+	}
+
+	filter.YearMode = mode
+
+	if filter.SubmitDates, err = dateRange(p, "submit"); err != nil {
+		return filter, err
+	}
+	if filter.DecisionDates, err = dateRange(p, "decision"); err != nil {
+		return filter, err
+	}
+	if filter.StartDates, err = dateRange(p, "start"); err != nil {
+		return filter, err
+	}
+	if filter.EndDates, err = dateRange(p, "end"); err != nil {
+		return filter, err
+	}
+
+	//s=all includes every status, otherwise s lists statuses and defaults to CERTIFIED
+	for _, status := range multiValue(p, "s") {
+		status = strings.ToUpper(status)
+		if status == "ALL" {
+			filter.AnyCaseStatus = true
+		} else if domain.IsCaseStatus(status) {
+			filter.CaseStatuses = append(filter.CaseStatuses, status)
+		} else {
+			return filter, errors.New("unknown case status " + status)
+		}
+	}
+
+	return filter, nil
+}
+
+//pageCriteria reads the paging, sorting and facets of a search listing cases
+func pageCriteria(p url.Values, filter *domain.SearchCriteria) error {
+	filter.PageSize, _ = strconv.Atoi(p.Get("size"))
+
+	filter.SortBy = p.Get("sort")
+	if len(filter.SortBy) > 0 && !domain.IsSortKey(filter.SortBy) {
+		return errors.New("unknown sort key " + filter.SortBy)
+	}
+	if filter.SortBy == domain.SortByDistance && len(filter.Zipcode) == 0 {
+		return errors.New("sorting by distance needs a zipcode")
+	}
+
+	if f := p.Get("facets"); len(f) > 0 {
+		filter.Facets = strings.Split(f, ",")
+		for _, facet := range filter.Facets {
+			if !domain.IsFacet(facet) {
+				return errors.New("unknown facet " + facet)
+			}
+		}
+	}
+
+	filter.Cursor = p.Get("cursor")
+	filter.SortDescending = p.Get("dir") == "desc"
+	return nil
+}
+
+//dateRange reads <name>_from and <name>_to as ISO dates, either may be left out
+func dateRange(p url.Values, name string) (domain.DateRange, error) {
+	var r domain.DateRange
+	var err error
+
+	if from := p.Get(name + "_from"); len(from) > 0 {
+		if r.From, err = time.Parse(isoDateLayout, from); err != nil {
+			return r, errors.New(name + "_from must be a date like 2019-10-01")
+		}
+	}
+	if to := p.Get(name + "_to"); len(to) > 0 {
+		if r.To, err = time.Parse(isoDateLayout, to); err != nil {
+			return r, errors.New(name + "_to must be a date like 2019-10-01")
+		}
+	}
+	if !r.From.IsZero() && !r.To.IsZero() && r.From.After(r.To) {
+		return r, errors.New(name + "_from is after " + name + "_to")
+	}
+	return r, nil
+}
+
+//multiValue reads a parameter given repeated (s=a&s=b) or comma separated (s=a,b)
+func multiValue(p url.Values, key string) []string {
+	var values []string
+	for _, value := range p[key] {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); len(v) > 0 {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
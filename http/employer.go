@@ -0,0 +1,46 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	domain "github.com/kk3399/empnearme/domain"
+	logWriter "github.com/kk3399/empnearme/log"
+)
+
+//EmployerHandler handles /employer/{id} profile requests
+type EmployerHandler struct {
+	LcaRepo   domain.LcaRepo
+	PermRepo  domain.PermRepo
+	UscisRepo domain.UscisRepo
+	Log       logWriter.Writer
+}
+
+func (employerHandler EmployerHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	id, _ := shiftPath(req.URL.Path)
+	if len(id) == 0 {
+		http.Error(res, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	profile, err := employerHandler.LcaRepo.GetEmployerProfile(id)
+	if err == domain.ErrNotFound {
+		http.Error(res, "Not Found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		employerHandler.Log.Write(err)
+		http.Error(res, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if employerHandler.PermRepo != nil {
+		profile.Perm = employerHandler.PermRepo.GetEmployerHistory(profile.Name, profile.Fein)
+	}
+	if employerHandler.UscisRepo != nil {
+		profile.Petitions = employerHandler.UscisRepo.GetEmployerPetitions(profile.Name, profile.Fein)
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	json.NewEncoder(res).Encode(profile)
+}
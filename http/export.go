@@ -0,0 +1,187 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+//response formats of /lca, chosen with format= or else the Accept header
+const (
+	formatJSON    = "json"
+	formatCsv     = "csv"
+	formatGeoJSON = "geojson"
+)
+
+//responseFormat is the format a request asks /lca results in, json by default
+func responseFormat(req *http.Request) (string, error) {
+	switch format := strings.ToLower(req.URL.Query().Get("format")); format {
+	case "":
+	case formatJSON, formatCsv, formatGeoJSON:
+		return format, nil
+	default:
+		return "", errors.New("unknown format " + format)
+	}
+
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(accept, ";", 2)[0])
+		if mediaType == "text/csv" {
+			return formatCsv, nil
+		}
+		if mediaType == "application/geo+json" {
+			return formatGeoJSON, nil
+		}
+		if mediaType == "application/json" {
+			break
+		}
+	}
+	return formatJSON, nil
+}
+
+//csvColumn is one column of a csv export, named once for good so spreadsheets built on exports keep working
+type csvColumn struct {
+	name  string
+	value func(lca domain.Lca) string
+}
+
+//csvColumns of a csv export, in the order they are written when columns= does not choose any
+var csvColumns = []csvColumn{
+	{"case_number", func(lca domain.Lca) string { return lca.Case_number }},
+	{"case_status", func(lca domain.Lca) string { return lca.Case_status }},
+	{"fiscal_year", func(lca domain.Lca) string { return strconv.Itoa(lca.Year) }},
+	{"visa_class", func(lca domain.Lca) string { return lca.VisaClass() }},
+	{"submit_date", func(lca domain.Lca) string { return csvDate(lca.Submit_date) }},
+	{"decision_date", func(lca domain.Lca) string { return csvDate(lca.Decision_date) }},
+	{"start_date", func(lca domain.Lca) string { return csvDate(lca.Start_date) }},
+	{"end_date", func(lca domain.Lca) string { return csvDate(lca.End_date) }},
+	{"employer_name", func(lca domain.Lca) string { return lca.Employer_name }},
+	{"employer_address", func(lca domain.Lca) string { return lca.Employer_address }},
+	{"employer_city", func(lca domain.Lca) string { return lca.Employer_city }},
+	{"employer_state", func(lca domain.Lca) string { return lca.Employer_state }},
+	{"employer_zip", func(lca domain.Lca) string { return strings.TrimPrefix(lca.Employer_zip, "1") }},
+	{"employer_fein", func(lca domain.Lca) string { return lca.Employer_fein }},
+	{"job_title", func(lca domain.Lca) string { return lca.Job_title }},
+	{"soc_code", func(lca domain.Lca) string { return lca.Soc_code }},
+	{"soc_name", func(lca domain.Lca) string { return lca.Soc_name }},
+	{"naics_code", func(lca domain.Lca) string { return lca.Naics_code }},
+	{"total_workers", func(lca domain.Lca) string { return strconv.Itoa(lca.Total_workers) }},
+	{"full_time", func(lca domain.Lca) string { return lca.Full_time }},
+	{"wage_rate", func(lca domain.Lca) string { return lca.Wage_rate }},
+	{"wage_unit", func(lca domain.Lca) string { return lca.Wage_unit }},
+	{"wage_level", func(lca domain.Lca) string { return lca.Wage_level }},
+	{"pay", func(lca domain.Lca) string { return strconv.Itoa(lca.Pay) }},
+	{"h1b_dependent", func(lca domain.Lca) string { return lca.H1b_dependent }},
+	{"willful_violator", func(lca domain.Lca) string { return lca.Willful_voilator }},
+	{"work_location_city", func(lca domain.Lca) string { return lca.Work_location_city }},
+	{"work_location_state", func(lca domain.Lca) string { return lca.Work_location_state }},
+	{"work_location_zip", func(lca domain.Lca) string { return lca.Work_location_zip }},
+}
+
+//csvDate formats a date for spreadsheets, empty when the case has none
+func csvDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(isoDateLayout)
+}
+
+//exportColumns reads columns=case_number,pay,... choosing the columns of a csv export and their order,
+//each column at most once
+func exportColumns(p url.Values) ([]csvColumn, error) {
+	names := p.Get("columns")
+	if len(names) == 0 {
+		return csvColumns, nil
+	}
+
+	var columns []csvColumn
+	chosen := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if chosen[name] {
+			return nil, errors.New("repeated column " + name)
+		}
+		found := false
+		for _, column := range csvColumns {
+			if column.name == name {
+				columns = append(columns, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("unknown column " + name)
+		}
+		chosen[name] = true
+	}
+	return columns, nil
+}
+
+//csvPageSize is how many results a csv export reads at a time, the largest page the repo returns
+const csvPageSize = 5000
+
+//csvPageTimeout is how long writing one page of a csv export may take, the deadline moves
+//with each page so exports of any size outlast the server's WriteTimeout
+const csvPageTimeout = 30 * time.Second
+
+//pageHeaders keep paging the same as json for GeoJSON: X-Total-Count has the
+//number of results and X-Next-Cursor the cursor of the next page
+func pageHeaders(res http.ResponseWriter, lcas domain.LcaIterator) {
+	res.Header().Set("X-Total-Count", strconv.Itoa(lcas.Total()))
+	if cursor := lcas.NextCursor(); len(cursor) > 0 {
+		res.Header().Set("X-Next-Cursor", cursor)
+	}
+}
+
+//writeCsv streams every result from the first page on, row by row as they are read. Later
+//pages are searched with the cursor of the one before and flushed as they are written, so the
+//export needs no paging; X-Total-Count still has the number of results
+func writeCsv(res http.ResponseWriter, lcaRepo domain.LcaRepo, filter domain.SearchCriteria, lcas domain.LcaIterator, columns []csvColumn) error {
+	res.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	res.Header().Set("Content-Disposition", `attachment; filename="lca.csv"`)
+	res.Header().Set("X-Total-Count", strconv.Itoa(lcas.Total()))
+	res.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(res)
+	w := csv.NewWriter(res)
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		row[i] = column.name
+	}
+	if err := w.Write(row); err != nil {
+		return err
+	}
+
+	for {
+		//recorders and other writers without deadlines just keep writing
+		controller.SetWriteDeadline(time.Now().Add(csvPageTimeout))
+		for lcas.Next() {
+			lca := lcas.Lca()
+			for i, column := range columns {
+				row[i] = column.value(lca)
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+		controller.Flush()
+
+		if filter.Cursor = lcas.NextCursor(); len(filter.Cursor) == 0 {
+			return nil
+		}
+		next, err := lcaRepo.Get(filter)
+		if err != nil {
+			return err
+		}
+		lcas = next
+	}
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+//fakeLcaRepo serves the same cases for any search, a page at a time with the offset as
+//cursor, and the points of its zipcodes
+type fakeLcaRepo struct {
+	domain.LcaRepo
+	lcas   []domain.Lca
+	points map[string]domain.GeoPoint
+}
+
+func (lcaRepo fakeLcaRepo) Get(searchCriteria domain.SearchCriteria) (domain.LcaIterator, error) {
+	offset := 0
+	if len(searchCriteria.Cursor) > 0 {
+		var err error
+		if offset, err = strconv.Atoi(searchCriteria.Cursor); err != nil || offset > len(lcaRepo.lcas) {
+			return nil, domain.ErrInvalidCursor
+		}
+	}
+	end := len(lcaRepo.lcas)
+	if searchCriteria.PageSize > 0 && offset+searchCriteria.PageSize < end {
+		end = offset + searchCriteria.PageSize
+	}
+	it := &fakeLcas{lcas: lcaRepo.lcas[offset:end], total: len(lcaRepo.lcas)}
+	if end < len(lcaRepo.lcas) {
+		it.nextCursor = strconv.Itoa(end)
+	}
+	return it, nil
+}
+
+func (lcaRepo fakeLcaRepo) GetZipcodePoint(zipcode string) (domain.GeoPoint, bool) {
+	point, ok := lcaRepo.points[zipcode]
+	return point, ok
+}
+
+type fakeLcas struct {
+	lcas       []domain.Lca
+	i          int
+	total      int
+	nextCursor string
+}
+
+func (it *fakeLcas) Next() bool                             { it.i++; return it.i <= len(it.lcas) }
+func (it *fakeLcas) Lca() domain.Lca                        { return it.lcas[it.i-1] }
+func (it *fakeLcas) Total() int                             { return it.total }
+func (it *fakeLcas) PageSize() int                          { return len(it.lcas) }
+func (it *fakeLcas) NextCursor() string                     { return it.nextCursor }
+func (it *fakeLcas) Facets() map[string][]domain.FacetCount { return nil }
+
+func TestResponseFormat(t *testing.T) {
+	for _, test := range []struct {
+		query  string
+		accept string
+		want   string
+	}{
+		{"", "", formatJSON},
+		{"", "text/csv", formatCsv},
+		{"", "application/json, text/csv", formatJSON},
+		{"", "text/html;q=0.9, text/csv;q=0.8", formatCsv},
+		{"", "application/geo+json", formatGeoJSON},
+		{"format=CSV", "application/json", formatCsv},
+		{"format=geojson", "text/csv", formatGeoJSON},
+	} {
+		req := httptest.NewRequest("GET", "/lca?"+test.query, nil)
+		req.Header.Set("Accept", test.accept)
+		if got, err := responseFormat(req); err != nil || got != test.want {
+			t.Errorf("%s, Accept %s: got %s, %v; want %s", test.query, test.accept, got, err, test.want)
+		}
+	}
+
+	if _, err := responseFormat(httptest.NewRequest("GET", "/lca?format=xml", nil)); err == nil {
+		t.Errorf("got no error; want unknown formats rejected")
+	}
+}
+
+func TestExportColumns(t *testing.T) {
+	columns, err := exportColumns(url.Values{"columns": {"pay, case_number"}})
+	if err != nil || len(columns) != 2 || columns[0].name != "pay" || columns[1].name != "case_number" {
+		t.Errorf("got %v, %v; want pay then case_number", columns, err)
+	}
+	if columns, _ := exportColumns(url.Values{}); len(columns) != len(csvColumns) {
+		t.Errorf("got %d columns; want all %d", len(columns), len(csvColumns))
+	}
+	if _, err := exportColumns(url.Values{"columns": {"case_number,salary"}}); err == nil {
+		t.Errorf("got no error; want unknown columns rejected")
+	}
+	if _, err := exportColumns(url.Values{"columns": {"pay,case_number,pay"}}); err == nil {
+		t.Errorf("got no error; want repeated columns rejected")
+	}
+}
+
+func TestLcaCsvExport(t *testing.T) {
+	lcas := []domain.Lca{
+		{Case_number: "I-1", Employer_name: "ACME, INC", Employer_zip: "105001", Pay: 120000},
+		{Case_number: "I-2", Employer_name: "ACME, INC", Employer_zip: "160523", Pay: 95000},
+	}
+	lcaHandler := LcaHandler{LcaRepo: fakeLcaRepo{lcas: lcas}}
+
+	res := httptest.NewRecorder()
+	lcaHandler.ServeHTTP(res, httptest.NewRequest("GET", "/lca?e=acme&format=csv&columns=case_number,employer_name,employer_zip,pay", nil))
+	if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("got %d %s; want a csv", res.Code, res.Header().Get("Content-Type"))
+	}
+	if res.Header().Get("X-Total-Count") != "2" || len(res.Header().Get("X-Next-Cursor")) > 0 {
+		t.Errorf("got %v; want the total and no next page", res.Header())
+	}
+	want := "case_number,employer_name,employer_zip,pay\nI-1,\"ACME, INC\",05001,120000\nI-2,\"ACME, INC\",60523,95000\n"
+	if got := res.Body.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	res = httptest.NewRecorder()
+	lcaHandler.ServeHTTP(res, httptest.NewRequest("GET", "/lca?e=acme&format=csv&columns=case_number,salary", nil))
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("got %d; want unknown columns to be a bad request", res.Code)
+	}
+}
+
+func TestLcaCsvExportPages(t *testing.T) {
+	lcas := make([]domain.Lca, csvPageSize*2+1)
+	for i := range lcas {
+		lcas[i] = domain.Lca{Case_number: "I-" + strconv.Itoa(i)}
+	}
+	lcaHandler := LcaHandler{LcaRepo: fakeLcaRepo{lcas: lcas}}
+
+	res := httptest.NewRecorder()
+	lcaHandler.ServeHTTP(res, httptest.NewRequest("GET", "/lca?e=acme&format=csv&columns=case_number", nil))
+	rows := strings.Split(strings.TrimSuffix(res.Body.String(), "\n"), "\n")
+	if len(rows) != len(lcas)+1 || rows[len(rows)-1] != "I-"+strconv.Itoa(len(lcas)-1) {
+		t.Errorf("got %d rows ending with %s; want a header and all %d cases", len(rows), rows[len(rows)-1], len(lcas))
+	}
+
+	res = httptest.NewRecorder()
+	lcaHandler.ServeHTTP(res, httptest.NewRequest("GET", "/lca?e=acme&format=csv&columns=case_number&cursor="+strconv.Itoa(csvPageSize*2), nil))
+	if want := "case_number\nI-" + strconv.Itoa(csvPageSize*2) + "\n"; res.Body.String() != want {
+		t.Errorf("got %q; want the rest from the cursor on %q", res.Body.String(), want)
+	}
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+//points of a GeoJSON response, at= places each case at its employer's headquarters or its worksite
+const (
+	pointAtHq       = "hq"
+	pointAtWorksite = "worksite"
+)
+
+type geoJSONCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string       `json:"type"`
+	Geometry   geoJSONPoint `json:"geometry"`
+	Properties interface{}  `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+//zipCluster is the feature of all cases of a page at one zipcode
+type zipCluster struct {
+	Zipcode string
+	Count   int
+	Workers int
+}
+
+//geoJSONOptions reads at=hq|worksite, hq by default, and cluster=1 merging the cases at one zipcode
+func geoJSONOptions(p url.Values) (at string, cluster bool, err error) {
+	at = strings.ToLower(p.Get("at"))
+	if len(at) == 0 {
+		at = pointAtHq
+	}
+	if at != pointAtHq && at != pointAtWorksite {
+		return "", false, errors.New("at must be " + pointAtHq + " or " + pointAtWorksite)
+	}
+	return at, p.Get("cluster") == "1", nil
+}
+
+//pointZipcode is the five digit zipcode a case is placed at
+func pointZipcode(lca domain.Lca, at string) string {
+	if at == pointAtWorksite {
+		return lca.Work_location_zip
+	}
+	return strings.TrimPrefix(lca.Employer_zip, "1")
+}
+
+//writeGeoJSON writes a page of results as a FeatureCollection of points at zipcode centroids,
+//with the case as the properties of each point. Clustered, each zipcode of the page is one
+//point counting its cases. Cases at zipcodes without a centroid are left out
+func writeGeoJSON(res http.ResponseWriter, lcaRepo domain.LcaRepo, lcas domain.LcaIterator, at string, cluster bool) error {
+	collection := geoJSONCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	clusters := make(map[string]*zipCluster)
+
+	for lcas.Next() {
+		lca := lcas.Lca()
+		zipcode := pointZipcode(lca, at)
+		if c, ok := clusters[zipcode]; ok {
+			c.Count++
+			c.Workers += lca.Total_workers
+			continue
+		}
+		point, ok := lcaRepo.GetZipcodePoint(zipcode)
+		if !ok {
+			continue
+		}
+
+		feature := geoJSONFeature{Type: "Feature", Properties: lca,
+			Geometry: geoJSONPoint{Type: "Point", Coordinates: [2]float64{point.Long, point.Lat}}}
+		if cluster {
+			clusters[zipcode] = &zipCluster{Zipcode: zipcode, Count: 1, Workers: lca.Total_workers}
+			feature.Properties = clusters[zipcode]
+		}
+		collection.Features = append(collection.Features, feature)
+	}
+
+	res.Header().Set("Content-Type", "application/geo+json")
+	pageHeaders(res, lcas)
+	res.WriteHeader(http.StatusOK)
+	return json.NewEncoder(res).Encode(collection)
+}
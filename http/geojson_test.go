@@ -0,0 +1,76 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+//geoJSONResponse decodes a FeatureCollection with the properties left raw
+type geoJSONResponse struct {
+	Type     string
+	Features []struct {
+		Type       string
+		Geometry   geoJSONPoint
+		Properties json.RawMessage
+	}
+}
+
+func TestLcaGeoJSON(t *testing.T) {
+	lcaHandler := LcaHandler{LcaRepo: fakeLcaRepo{
+		lcas: []domain.Lca{
+			{Case_number: "I-1", Employer_name: "ACME", Employer_zip: "107302", Work_location_zip: "60523", Total_workers: 1},
+			{Case_number: "I-2", Employer_name: "ACME", Employer_zip: "107302", Work_location_zip: "60523", Total_workers: 2},
+			{Case_number: "I-3", Employer_name: "ACME", Employer_zip: "160523", Work_location_zip: "99999", Total_workers: 3},
+		},
+		points: map[string]domain.GeoPoint{"07302": {Lat: 40.72, Long: -74.04}, "60523": {Lat: 41.84, Long: -87.95}},
+	}}
+
+	get := func(query string) geoJSONResponse {
+		res := httptest.NewRecorder()
+		lcaHandler.ServeHTTP(res, httptest.NewRequest("GET", "/lca?e=acme&format=geojson"+query, nil))
+		if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "application/geo+json" || res.Header().Get("X-Total-Count") != "3" {
+			t.Fatalf("%s: got %d %v; want GeoJSON", query, res.Code, res.Header())
+		}
+		var collection geoJSONResponse
+		if err := json.NewDecoder(res.Body).Decode(&collection); err != nil || collection.Type != "FeatureCollection" {
+			t.Fatalf("%s: got %v, %v; want a FeatureCollection", query, collection, err)
+		}
+		return collection
+	}
+
+	collection := get("")
+	if len(collection.Features) != 3 || collection.Features[0].Geometry.Coordinates != [2]float64{-74.04, 40.72} {
+		t.Fatalf("got %+v; want a point per case at its headquarters, longitude first", collection.Features)
+	}
+	var lca domain.Lca
+	if json.Unmarshal(collection.Features[2].Properties, &lca); lca.Case_number != "I-3" {
+		t.Errorf("got %s; want the case as the properties", collection.Features[2].Properties)
+	}
+
+	collection = get("&at=worksite")
+	if len(collection.Features) != 2 || collection.Features[0].Geometry.Coordinates != [2]float64{-87.95, 41.84} {
+		t.Errorf("got %+v; want the cases at worksites with a centroid", collection.Features)
+	}
+
+	collection = get("&cluster=1")
+	var clusters []zipCluster
+	for _, feature := range collection.Features {
+		var c zipCluster
+		json.Unmarshal(feature.Properties, &c)
+		clusters = append(clusters, c)
+	}
+	want := []zipCluster{{Zipcode: "07302", Count: 2, Workers: 3}, {Zipcode: "60523", Count: 1, Workers: 3}}
+	if len(clusters) != 2 || clusters[0] != want[0] || clusters[1] != want[1] {
+		t.Errorf("got %+v; want %+v", clusters, want)
+	}
+
+	res := httptest.NewRecorder()
+	lcaHandler.ServeHTTP(res, httptest.NewRequest("GET", "/lca?e=acme&format=geojson&at=home", nil))
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("got %d; want an unknown at to be a bad request", res.Code)
+	}
+}
@@ -4,10 +4,8 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"net/http"
 	"path"
-	"strconv"
 	"strings"
 	"time"
 
@@ -23,12 +21,26 @@ const (
 				 Allow:	      /about.html`
 )
 
-var templates = template.Must(template.ParseFiles("templates/list.html", "templates/single.html"))
-
 //LcaHandler handles all car http requests
 type LcaHandler struct {
-	LcaRepo domain.LcaRepo
-	Log     logWriter.Writer
+	LcaRepo   domain.LcaRepo
+	PermRepo  domain.PermRepo
+	UscisRepo domain.UscisRepo
+	Log       logWriter.Writer
+}
+
+//lcaResponse is one page of /lca results, NextCursor is empty on the last page.
+//Perm and Petitions have the PERM filings and USCIS petition decisions of each employer
+//on the page, by employer name
+type lcaResponse struct {
+	Results    []domain.Lca
+	Total      int
+	PageSize   int
+	NextCursor string
+	Facets     map[string][]domain.FacetCount
+	YearMode   string
+	Perm       map[string]domain.PermHistory
+	Petitions  map[string]domain.Petitions
 }
 
 //StaticHandler handles index.html
@@ -39,11 +51,24 @@ type EmpListHandler struct {
 	LcaRepo domain.LcaRepo
 }
 
+//TitleListHandler handles auto complete request on job titles
+type TitleListHandler struct {
+	LcaRepo domain.LcaRepo
+}
+
 //Handler for all incoming http requests
 type Handler struct {
-	LcaHandler     LcaHandler
-	StaticHandler  StaticHandler
-	EmpListHandler EmpListHandler
+	LcaHandler       LcaHandler
+	StaticHandler    StaticHandler
+	EmpListHandler   EmpListHandler
+	TitleListHandler TitleListHandler
+	SalaryHandler    SalaryHandler
+	EmployerHandler  EmployerHandler
+	TimelineHandler  TimelineHandler
+	CompareHandler   CompareHandler
+	OfferHandler     OfferHandler
+	CodesHandler     CodesHandler
+	PermHandler      PermHandler
 }
 
 //Serve http at predecided port
@@ -92,6 +117,7 @@ func Serve(handler Handler) error {
 }
 
 func makeHTTPServer() *http.Server {
+	//csv exports move their write deadline page by page, every other response has WriteTimeout
 	return &http.Server{
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 5 * time.Second,
@@ -114,6 +140,22 @@ func (h Handler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 		h.LcaHandler.ServeHTTP(res, req)
 	} else if head == "emps" {
 		h.EmpListHandler.ServeHTTP(res, req)
+	} else if head == "titles" {
+		h.TitleListHandler.ServeHTTP(res, req)
+	} else if head == "salary" {
+		h.SalaryHandler.ServeHTTP(res, req)
+	} else if head == "employer" {
+		h.EmployerHandler.ServeHTTP(res, req)
+	} else if head == "timeline" {
+		h.TimelineHandler.ServeHTTP(res, req)
+	} else if head == "compare" {
+		h.CompareHandler.ServeHTTP(res, req)
+	} else if head == "offer" {
+		h.OfferHandler.ServeHTTP(res, req)
+	} else if head == "perm" {
+		h.PermHandler.ServeHTTP(res, req)
+	} else if head == "codes" {
+		h.CodesHandler.ServeHTTP(res, req)
 	} else if head == "robots.txt" {
 		res.Header().Set("Content-Type", "text/plain")
 		res.WriteHeader(http.StatusOK)
@@ -143,34 +185,110 @@ func (empListHandler EmpListHandler) ServeHTTP(res http.ResponseWriter, req *htt
 	}
 }
 
+func (titleListHandler TitleListHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	p := req.URL.Query()
+	has := strings.ToUpper(p.Get("has"))
+	emp := strings.ToUpper(p.Get("e"))
+	zip := p.Get("z")
+	if len(has) > 2 {
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(http.StatusOK)
+		json.NewEncoder(res).Encode(titleListHandler.LcaRepo.GetJobTitles(has, emp, zip))
+	}
+}
+
 func (lcaHandler LcaHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 
 	p := req.URL.Query()
-	zip := p.Get("z")
-	job := p.Get("j")
-	x, _ := strconv.Atoi(p.Get("x"))
-	radius, _ := strconv.Atoi(p.Get("r"))
-	emp := strings.ToUpper(p.Get("e"))
-	payMin, _ := strconv.Atoi(p.Get("ps"))
-	payMax, _ := strconv.Atoi(p.Get("pe"))
-	year, _ := strconv.Atoi(p.Get("y"))
-	//h1After, _ := time.Parse("20060102", p.Get("d"))
+	filter, err := searchCriteria(p)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = pageCriteria(p, &filter); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	filter := domain.SearchCriteria{Radius: radius, Zipcode: zip, Employer: emp, PayMin: payMin, PayMax: payMax, H1Year: year, JobTitle: job}
-	if x > 0 {
-		filter.ExcludeH1Dependent = true
+	format, err := responseFormat(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var columns []csvColumn
+	if format == formatCsv {
+		if columns, err = exportColumns(p); err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.PageSize = csvPageSize
+	}
+	at, cluster, err := geoJSONOptions(p)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	lcas, err := lcaHandler.LcaRepo.Get(filter)
+	if err == domain.ErrInvalidCursor {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		lcaHandler.Log.Write(err)
+		http.Error(res, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if format == formatCsv {
+		if err = writeCsv(res, lcaHandler.LcaRepo, filter, lcas, columns); err != nil {
+			lcaHandler.Log.Write(err)
+		}
+		return
+	}
+	if format == formatGeoJSON {
+		if err = writeGeoJSON(res, lcaHandler.LcaRepo, lcas, at, cluster); err != nil {
+			lcaHandler.Log.Write(err)
+		}
+		return
 	}
 
+	response := lcaResponse{Total: lcas.Total(), PageSize: lcas.PageSize(), NextCursor: lcas.NextCursor(), Facets: lcas.Facets(),
+		YearMode: filter.YearMode}
+	response.Results = make([]domain.Lca, 0, lcas.PageSize())
+	for lcas.Next() {
+		response.Results = append(response.Results, lcas.Lca())
+	}
+	lcaHandler.employerHistories(&response)
+
 	res.Header().Set("Content-Type", "application/json")
 	res.WriteHeader(http.StatusOK)
-	json.NewEncoder(res).Encode(lcas)
-	//templates.ExecuteTemplate(res, "list.html", lcas)
+	json.NewEncoder(res).Encode(response)
+}
+
+//employerHistories adds the PERM filings and USCIS petitions of the employers of a page,
+//each left out when its data is not wired in
+func (lcaHandler LcaHandler) employerHistories(response *lcaResponse) {
+	feins := make(map[string]string)
+	for _, lca := range response.Results {
+		if len(feins[lca.Employer_name]) == 0 {
+			feins[lca.Employer_name] = lca.Employer_fein
+		}
+	}
 
+	if lcaHandler.PermRepo != nil {
+		response.Perm = make(map[string]domain.PermHistory)
+		for name, fein := range feins {
+			response.Perm[name] = lcaHandler.PermRepo.GetEmployerHistory(name, fein)
+		}
+	}
+	if lcaHandler.UscisRepo != nil {
+		response.Petitions = make(map[string]domain.Petitions)
+		for name, fein := range feins {
+			response.Petitions[name] = lcaHandler.UscisRepo.GetEmployerPetitions(name, fein)
+		}
+	}
 }
 
 // shiftPath splits off the first component of p, which will be cleaned of
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	domain "github.com/kk3399/empnearme/domain"
+	logWriter "github.com/kk3399/empnearme/log"
+)
+
+const (
+	defaultOfferRadius = 25
+	defaultOfferYears  = 3
+)
+
+//OfferHandler handles /offer?j=..&pay=..&z=.. requests ranking a salary among comparable cases,
+//years=3 keeps the cohort to the latest years as counted by ym
+type OfferHandler struct {
+	LcaRepo domain.LcaRepo
+	Log     logWriter.Writer
+}
+
+func (offerHandler OfferHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	p := req.URL.Query()
+
+	mode, err := yearMode(p)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	offer := domain.Offer{JobTitle: p.Get("j"), SocCode: p.Get("soc"), Zipcode: p.Get("z"), Radius: defaultOfferRadius, Years: defaultOfferYears,
+		YearMode: mode}
+	if len(strings.TrimSpace(offer.JobTitle)) == 0 && len(strings.TrimSpace(offer.SocCode)) == 0 {
+		http.Error(res, "offer needs a job title or SOC code", http.StatusBadRequest)
+		return
+	}
+
+	pay, err := strconv.Atoi(strings.NewReplacer("$", "", ",", "").Replace(p.Get("pay")))
+	if err != nil || pay <= 0 {
+		http.Error(res, "offer needs a yearly pay", http.StatusBadRequest)
+		return
+	}
+	offer.Pay = pay
+
+	if r, err := strconv.Atoi(p.Get("r")); err == nil {
+		offer.Radius = r
+	}
+	if years, err := strconv.Atoi(p.Get("years")); err == nil {
+		offer.Years = years
+	}
+
+	evaluation, err := offerHandler.LcaRepo.EvaluateOffer(offer)
+	if err != nil {
+		offerHandler.Log.Write(err)
+		http.Error(res, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	json.NewEncoder(res).Encode(evaluation)
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	domain "github.com/kk3399/empnearme/domain"
+	logWriter "github.com/kk3399/empnearme/log"
+)
+
+//PermHandler handles /perm searches and /perm/salary pay percentiles, filtered like /lca.
+//PERM cases have no employment start, so years default to the fiscal year
+type PermHandler struct {
+	PermRepo domain.PermRepo
+	Log      logWriter.Writer
+}
+
+//permResponse is one page of /perm results, NextCursor is empty on the last page
+type permResponse struct {
+	Results    []domain.Perm
+	Total      int
+	PageSize   int
+	NextCursor string
+	Facets     map[string][]domain.FacetCount
+	YearMode   string
+}
+
+func (permHandler PermHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	head, _ := shiftPath(req.URL.Path)
+	if head != "" && head != "salary" {
+		http.Error(res, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	p := req.URL.Query()
+	if len(p.Get("ym")) == 0 {
+		p.Set("ym", domain.YearModeFiscal)
+	}
+	filter, err := searchCriteria(p)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if head == "salary" {
+		permHandler.salary(res, filter, p.Get("group"))
+		return
+	}
+
+	if err = pageCriteria(p, &filter); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	perms, err := permHandler.PermRepo.Get(filter)
+	if err == domain.ErrInvalidCursor {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		permHandler.Log.Write(err)
+		http.Error(res, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	response := permResponse{Total: perms.Total(), PageSize: perms.PageSize(), NextCursor: perms.NextCursor(), Facets: perms.Facets(),
+		YearMode: filter.YearMode}
+	response.Results = make([]domain.Perm, 0, perms.PageSize())
+	for perms.Next() {
+		response.Results = append(response.Results, perms.Perm())
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	json.NewEncoder(res).Encode(response)
+}
+
+func (permHandler PermHandler) salary(res http.ResponseWriter, filter domain.SearchCriteria, groupBy string) {
+	if len(groupBy) > 0 && !domain.IsGroupBy(groupBy) {
+		http.Error(res, "cannot group by "+groupBy, http.StatusBadRequest)
+		return
+	}
+
+	stats, err := permHandler.PermRepo.GetPayStats(filter, groupBy)
+	if err != nil {
+		permHandler.Log.Write(err)
+		http.Error(res, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	json.NewEncoder(res).Encode(salaryResponse{Stats: stats, YearMode: filter.YearMode})
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	domain "github.com/kk3399/empnearme/domain"
+	logWriter "github.com/kk3399/empnearme/log"
+)
+
+//salaryResponse is the pay statistics of each group, years counted by YearMode
+type salaryResponse struct {
+	Stats    []domain.PayStats
+	YearMode string
+}
+
+//SalaryHandler handles pay percentile requests, filtered like /lca
+type SalaryHandler struct {
+	LcaRepo domain.LcaRepo
+	Log     logWriter.Writer
+}
+
+func (salaryHandler SalaryHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	p := req.URL.Query()
+	filter, err := searchCriteria(p)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	groupBy := p.Get("group")
+	if len(groupBy) > 0 && !domain.IsGroupBy(groupBy) {
+		http.Error(res, "cannot group by "+groupBy, http.StatusBadRequest)
+		return
+	}
+
+	stats, err := salaryHandler.LcaRepo.GetPayStats(filter, groupBy)
+	if err != nil {
+		salaryHandler.Log.Write(err)
+		http.Error(res, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	json.NewEncoder(res).Encode(salaryResponse{Stats: stats, YearMode: filter.YearMode})
+}
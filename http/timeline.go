@@ -0,0 +1,59 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	domain "github.com/kk3399/empnearme/domain"
+	logWriter "github.com/kk3399/empnearme/log"
+)
+
+//timelineResponse is the filings of each month or quarter, years of the y filter counted by YearMode
+type timelineResponse struct {
+	Buckets  []domain.TimeBucket
+	YearMode string
+}
+
+//TimelineHandler handles filing time series requests, filtered like /lca
+type TimelineHandler struct {
+	LcaRepo domain.LcaRepo
+	Log     logWriter.Writer
+}
+
+func (timelineHandler TimelineHandler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	p := req.URL.Query()
+	filter, err := searchCriteria(p)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	interval := p.Get("interval")
+	if len(interval) == 0 {
+		interval = domain.IntervalMonth
+	}
+	if interval != domain.IntervalMonth && interval != domain.IntervalQuarter {
+		http.Error(res, "interval must be month or quarter", http.StatusBadRequest)
+		return
+	}
+
+	dateField := p.Get("date")
+	if len(dateField) == 0 {
+		dateField = domain.SortBySubmitDate
+	}
+	if !domain.IsDateField(dateField) {
+		http.Error(res, "date must be submitted, decided or start", http.StatusBadRequest)
+		return
+	}
+
+	series, err := timelineHandler.LcaRepo.GetTimeSeries(filter, interval, dateField)
+	if err != nil {
+		timelineHandler.Log.Write(err)
+		http.Error(res, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	json.NewEncoder(res).Encode(timelineResponse{Buckets: series, YearMode: filter.YearMode})
+}
@@ -14,13 +14,29 @@ func main() {
 
 	logWriter.Init()
 	logger := logWriter.Writer{}
-	repo := store.Init(logger)
+	manifest, err := store.ReadManifest("")
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+	repo := store.Init(logger, manifest)
+	permRepo := store.InitPerm(logger, repo, manifest)
+	uscisRepo := store.InitUscis(logger, manifest)
 
 	logger.Info("db is open")
 
-	lcaHandler := http.LcaHandler{LcaRepo: repo, Log: logger}
+	lcaHandler := http.LcaHandler{LcaRepo: repo, PermRepo: permRepo, UscisRepo: uscisRepo, Log: logger}
 	empListHandler := http.EmpListHandler{LcaRepo: repo}
-	httpHandler := http.Handler{LcaHandler: lcaHandler, EmpListHandler: empListHandler}
+	titleListHandler := http.TitleListHandler{LcaRepo: repo}
+	salaryHandler := http.SalaryHandler{LcaRepo: repo, Log: logger}
+	employerHandler := http.EmployerHandler{LcaRepo: repo, PermRepo: permRepo, UscisRepo: uscisRepo, Log: logger}
+	timelineHandler := http.TimelineHandler{LcaRepo: repo, Log: logger}
+	compareHandler := http.CompareHandler{LcaRepo: repo, Log: logger}
+	offerHandler := http.OfferHandler{LcaRepo: repo, Log: logger}
+	codesHandler := http.CodesHandler{LcaRepo: repo, Log: logger}
+	permHandler := http.PermHandler{PermRepo: permRepo, Log: logger}
+	httpHandler := http.Handler{LcaHandler: lcaHandler, EmpListHandler: empListHandler, TitleListHandler: titleListHandler,
+		SalaryHandler: salaryHandler, EmployerHandler: employerHandler, TimelineHandler: timelineHandler, CompareHandler: compareHandler,
+		OfferHandler: offerHandler, CodesHandler: codesHandler, PermHandler: permHandler}
 	httpHandler.StartProfiling()
 	logger.Write(http.Serve(httpHandler))
 }
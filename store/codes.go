@@ -0,0 +1,122 @@
+package store
+
+import (
+	"encoding/csv"
+	"os"
+	"sort"
+	"strings"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+//code tables are offline csv files of code,title lines, any level of the hierarchy may be listed.
+//The bundled files are the published SOC 2018 and NAICS 2017 structures, followed for SOC by
+//the 2010 codes older DOL files were filed under. An earlier file names a code first
+var codeFileNames = map[string][]string{
+	domain.CodeSystemSoc:   {"soccodes.csv", "soc2010codes.csv"},
+	domain.CodeSystemNaics: {"naicscodes.csv"},
+}
+
+//codeTable names the codes of one system by code
+type codeTable map[string]string
+
+//loadCodes reads the code tables of every system, a missing table leaves its codes out
+func (lcaRepo LcaRepo) loadCodes() map[string]codeTable {
+	codes := make(map[string]codeTable)
+	for system, fileNames := range codeFileNames {
+		codes[system] = make(codeTable)
+		for _, fileName := range fileNames {
+			table, err := loadCodeTable(fileName)
+			if err != nil {
+				lcaRepo.log.Error(err.Error())
+			}
+			for code, title := range table {
+				if _, ok := codes[system][code]; !ok {
+					codes[system][code] = title
+				}
+			}
+		}
+	}
+	lcaRepo.addCaseCodes(codes)
+	return codes
+}
+
+//addCaseCodes lists the codes cases were filed under that the table lacks, SOC codes are named
+//with the title DOL files carry with each case while NAICS codes stay untitled
+func (lcaRepo LcaRepo) addCaseCodes(codes map[string]codeTable) {
+	for _, lca := range lcaRepo.store.Cases {
+		code := strings.TrimSpace(strings.Split(lca.Soc_code, ".")[0])
+		if title, ok := codes[domain.CodeSystemSoc][code]; (!ok || len(title) == 0) && len(domain.CodeLevel(domain.CodeSystemSoc, code)) > 0 {
+			codes[domain.CodeSystemSoc][code] = strings.TrimSpace(lca.Soc_name)
+		}
+		code = strings.TrimSpace(lca.Naics_code)
+		if _, ok := codes[domain.CodeSystemNaics][code]; !ok && len(domain.CodeLevel(domain.CodeSystemNaics, code)) > 0 {
+			codes[domain.CodeSystemNaics][code] = ""
+		}
+	}
+}
+
+func loadCodeTable(fileName string) (codeTable, error) {
+	table := make(codeTable)
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return table, err
+	}
+	defer f.Close()
+
+	lines, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return table, err
+	}
+	for _, line := range lines {
+		if len(line) < 2 {
+			continue
+		}
+		table[strings.TrimSpace(line[0])] = strings.TrimSpace(line[1])
+	}
+	return table, nil
+}
+
+//GetCodes lists the codes of a system in code order, only those below under when given
+//and only those whose title contains has. Codes only seen in cases may have no title
+func (lcaRepo LcaRepo) GetCodes(system string, under string, has string) ([]domain.Code, error) {
+	table, ok := lcaRepo.codes[system]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	var parents []string
+	if len(under) > 0 {
+		if parents, ok = domain.CodePrefixes(system, under); !ok {
+			return nil, domain.ErrNotFound
+		}
+	}
+	has = strings.ToUpper(has)
+
+	codes := []domain.Code{}
+	for code, title := range table {
+		prefixes, ok := domain.CodePrefixes(system, code)
+		if !ok || (len(parents) > 0 && !below(prefixes, parents)) || !strings.Contains(strings.ToUpper(title), has) {
+			continue
+		}
+		codes = append(codes, domain.Code{Code: code, Title: title, Level: domain.CodeLevel(system, code)})
+	}
+
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].Code < codes[j].Code
+	})
+	return codes, nil
+}
+
+//below tells if a code is deeper in the hierarchy than, and within, a parent
+func below(prefixes []string, parents []string) bool {
+	for _, prefix := range prefixes {
+		for _, parent := range parents {
+			if len(prefix) > len(parent) && strings.HasPrefix(prefix, parent) {
+				return true
+			}
+		}
+	}
+	return false
+}
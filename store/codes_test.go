@@ -0,0 +1,144 @@
+package store
+
+import (
+	"strings"
+	"testing"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+func TestGetCodes(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Soc_code: "15-1252", Naics_code: "541511"},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "160523", Soc_code: "15-1132.00", Soc_name: "SOFTWARE DEVELOPERS, APPLICATIONS", Naics_code: "334413"},
+		domain.Lca{Case_number: "3", Employer_name: "ACME", Employer_zip: "160523", Soc_code: "17-2071", Naics_code: "541330"},
+	)
+
+	for _, test := range []struct {
+		searchCriteria domain.SearchCriteria
+		want           int
+	}{
+		{domain.SearchCriteria{Employer: "ACME", SocCodes: []string{"15-0000"}}, 2},
+		{domain.SearchCriteria{Employer: "ACME", SocCodes: []string{"15-1250"}}, 1},
+		{domain.SearchCriteria{Employer: "ACME", SocCodes: []string{"15-1132", "17-2000"}}, 2},
+		{domain.SearchCriteria{Employer: "ACME", NaicsCodes: []string{"54"}}, 2},
+		{domain.SearchCriteria{Employer: "ACME", NaicsCodes: []string{"31-33"}}, 1},
+		{domain.SearchCriteria{Employer: "ACME", SocCodes: []string{"15-0000"}, NaicsCodes: []string{"5415"}}, 1},
+	} {
+		it, _ := lcaRepo.Get(test.searchCriteria)
+		if it.Total() != test.want {
+			t.Errorf("%+v: got %d cases; want %d", test.searchCriteria, it.Total(), test.want)
+		}
+	}
+
+	lcaRepo.codes = map[string]codeTable{
+		domain.CodeSystemSoc:   {"15-0000": "Computer and Mathematical Occupations", "15-1200": "Computer Occupations", "15-1252": "Software Developers", "17-0000": "Architecture and Engineering Occupations"},
+		domain.CodeSystemNaics: {"54": "Professional, Scientific, and Technical Services"},
+	}
+	lcaRepo.addCaseCodes(lcaRepo.codes)
+
+	codes, err := lcaRepo.GetCodes(domain.CodeSystemSoc, "15-0000", "")
+	if err != nil {
+		t.Fatalf("got %v; want no error", err)
+	}
+	var got []string
+	for _, code := range codes {
+		got = append(got, code.Code+" "+code.Level)
+	}
+	if want := "15-1132 detailed,15-1200 minor,15-1252 detailed"; strings.Join(got, ",") != want {
+		t.Errorf("got %v; want %s", got, want)
+	}
+
+	lcaRepo.codes[domain.CodeSystemSoc]["29-1210"] = "Physicians"
+	lcaRepo.codes[domain.CodeSystemSoc]["29-1216"] = "General Internal Medicine Physicians"
+	lcaRepo.codes[domain.CodeSystemSoc]["29-1221"] = "Pediatricians, General"
+	if codes, _ := lcaRepo.GetCodes(domain.CodeSystemSoc, "29-1210", ""); len(codes) != 2 {
+		t.Errorf("got %v; want both physicians under their broad group", codes)
+	}
+
+	if codes, _ := lcaRepo.GetCodes(domain.CodeSystemSoc, "", "software"); len(codes) != 2 {
+		t.Errorf("got %v; want both software codes", codes)
+	}
+
+	codes, _ = lcaRepo.GetCodes(domain.CodeSystemNaics, "", "")
+	got = nil
+	for _, code := range codes {
+		got = append(got, code.Code+" "+code.Title)
+	}
+	if want := "334413 ,54 Professional, Scientific, and Technical Services,541330 ,541511 "; strings.Join(got, ",") != want {
+		t.Errorf("got %q; want the table and case codes %s", got, want)
+	}
+
+	if _, err := lcaRepo.GetCodes("isic", "", ""); err != domain.ErrNotFound {
+		t.Errorf("got %v; want ErrNotFound", err)
+	}
+}
+
+func TestGetByCodeWithoutIndex(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Soc_code: "15-1252", Naics_code: "541511"},
+		domain.Lca{Case_number: "2", Employer_name: "INITECH", Employer_zip: "175001", Soc_code: "15-1252", Naics_code: "334413", Visa_class: domain.VisaE3},
+		domain.Lca{Case_number: "3", Employer_name: "INITECH", Employer_zip: "175001", Soc_code: "17-2071", Naics_code: "541330"},
+	)
+
+	for _, test := range []struct {
+		searchCriteria domain.SearchCriteria
+		want           int
+	}{
+		{domain.SearchCriteria{SocCodes: []string{"15-1252"}}, 2},
+		{domain.SearchCriteria{NaicsCodes: []string{"54"}}, 2},
+		{domain.SearchCriteria{VisaClasses: []string{domain.VisaE3}}, 1},
+	} {
+		it, _ := lcaRepo.Get(test.searchCriteria)
+		if it.Total() != test.want {
+			t.Errorf("%+v: got %d cases; want %d from a scan of every case", test.searchCriteria, it.Total(), test.want)
+		}
+	}
+}
+
+func TestCodeTables(t *testing.T) {
+	levels := map[string][]string{
+		domain.CodeSystemSoc:   {"major", "minor", "broad", "detailed"},
+		domain.CodeSystemNaics: {"sector", "subsector", "industry group", "industry", "national industry"},
+	}
+	for system, fileNames := range codeFileNames {
+		table := make(codeTable)
+		for _, fileName := range fileNames {
+			codes, err := loadCodeTable("../" + fileName)
+			if err != nil {
+				t.Fatalf("got %v; want %s to load", err, fileName)
+			}
+			for code, title := range codes {
+				if _, ok := table[code]; ok {
+					t.Errorf("%s: got %s again; want each code in one table", fileName, code)
+				}
+				table[code] = title
+			}
+		}
+
+		//every code sits under a code of each level above its own
+		for code, title := range table {
+			level := domain.CodeLevel(system, code)
+			if len(level) == 0 || len(title) == 0 {
+				t.Errorf("%s %s %q: got level %q; want a titled %s code", system, code, title, level, system)
+				continue
+			}
+			prefixes, _ := domain.CodePrefixes(system, code)
+			above := make(map[string]bool)
+			for parent := range table {
+				parents, _ := domain.CodePrefixes(system, parent)
+				if below(prefixes, parents) {
+					above[domain.CodeLevel(system, parent)] = true
+				}
+			}
+			for _, parentLevel := range levels[system] {
+				if parentLevel == level {
+					break
+				}
+				if !above[parentLevel] {
+					t.Errorf("%s %s: got no %s above it", system, code, parentLevel)
+				}
+			}
+		}
+	}
+}
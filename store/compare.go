@@ -0,0 +1,78 @@
+package store
+
+import (
+	"sort"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+//CompareEmployers runs the same search for each employer id and lines the results up.
+//The certified rate is over cases of every status, the other numbers follow the criteria's statuses
+func (lcaRepo LcaRepo) CompareEmployers(ids []string, searchCriteria domain.SearchCriteria) ([]domain.EmployerComparison, error) {
+	statuses := lcaRepo.index().caseStatuses(searchCriteria)
+	searchCriteria.AnyCaseStatus = true
+
+	comparisons := make([]domain.EmployerComparison, 0, len(ids))
+	levelPays := make([]map[string][]int, 0, len(ids))
+	levels := make(map[string]bool)
+
+	for _, id := range ids {
+		name, ok := lcaRepo.employerName(id)
+		if !ok {
+			return nil, domain.ErrNotFound
+		}
+
+		comparison := domain.EmployerComparison{Id: domain.EmployerID(name), Name: name}
+
+		var latest domain.Lca
+		for _, lca := range lcaRepo.employerCases(name) {
+			if latest.Submit_date.IsZero() || lca.Submit_date.After(latest.Submit_date) {
+				latest = lca
+			}
+		}
+		comparison.H1bDependent = latest.H1b_dependent == "Y"
+		comparison.WillfulViolator = latest.Willful_voilator == "Y"
+
+		pays := make(map[string][]int)
+		var all, certified int
+		searchCriteria.Employer = name
+		for _, casenum := range lcaRepo.search(searchCriteria) {
+			lca := lcaRepo.store.Cases[casenum]
+			all++
+			if lca.Case_status == domain.StatusCertified {
+				certified++
+			}
+			if statuses != nil && !statuses[lca.Case_status] {
+				continue
+			}
+			comparison.Filings++
+			comparison.Workers += lca.Total_workers
+			level := domain.NormalizeWageLevel(lca.Wage_level)
+			levels[level] = true
+			if lca.Pay > 0 {
+				pays[level] = append(pays[level], lca.Pay)
+			}
+		}
+		if all > 0 {
+			comparison.CertifiedRate = float64(certified) / float64(all)
+		}
+
+		comparisons = append(comparisons, comparison)
+		levelPays = append(levelPays, pays)
+	}
+
+	var levelOrder []string
+	for level := range levels {
+		levelOrder = append(levelOrder, level)
+	}
+	sort.Strings(levelOrder)
+
+	for i := range comparisons {
+		for _, level := range levelOrder {
+			stats := payStats(level, levelPays[i][level])
+			comparisons[i].Levels = append(comparisons[i].Levels, domain.LevelPay{Level: level, Filings: stats.Count, MedianPay: stats.P50})
+		}
+	}
+
+	return comparisons, nil
+}
@@ -0,0 +1,36 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+func TestCompareEmployers(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Case_status: "CERTIFIED", Employer_name: "ACME", Employer_zip: "160523", Job_title: "DATA ENGINEER", Wage_level: "II", Pay: 100000},
+		domain.Lca{Case_number: "2", Case_status: "DENIED", Employer_name: "ACME", Employer_zip: "160523", Job_title: "DATA ENGINEER", Wage_level: "II", Pay: 120000},
+		domain.Lca{Case_number: "3", Case_status: "CERTIFIED", Employer_name: "INITECH", Employer_zip: "160523", Job_title: "DATA ENGINEER", Wage_level: "III", Pay: 150000, H1b_dependent: "Y", Submit_date: time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)},
+		domain.Lca{Case_number: "4", Case_status: "CERTIFIED", Employer_name: "INITECH", Employer_zip: "160523", Job_title: "ANALYST", Wage_level: "I", Pay: 70000, Submit_date: time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC)},
+	)
+
+	comparisons, err := lcaRepo.CompareEmployers([]string{domain.EmployerID("ACME"), "INITECH"}, domain.SearchCriteria{JobTitle: "ENGINEER"})
+	if err != nil {
+		t.Fatalf("got %v; want no error", err)
+	}
+	acme, initech := comparisons[0], comparisons[1]
+	if acme.Filings != 1 || acme.CertifiedRate != 0.5 || acme.H1bDependent {
+		t.Errorf("got %+v; want 1 certified ACME filing out of 2", acme)
+	}
+	if !initech.H1bDependent || initech.Filings != 1 {
+		t.Errorf("got %+v; want 1 dependent INITECH filing", initech)
+	}
+	if len(acme.Levels) != 2 || len(initech.Levels) != 2 || acme.Levels[0] != (domain.LevelPay{Level: "II", Filings: 1, MedianPay: 100000}) || initech.Levels[0].Filings != 0 {
+		t.Errorf("got %+v and %+v; want both aligned on levels II and III", acme.Levels, initech.Levels)
+	}
+
+	if _, err := lcaRepo.CompareEmployers([]string{"ACME", "nope"}, domain.SearchCriteria{}); err != domain.ErrNotFound {
+		t.Errorf("got %v; want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+const (
+	constTopTitlesCap    = 10
+	constWorksiteZipsCap = 50
+)
+
+//employerIDs maps each employer's id to its name
+func employerIDs(employerCases map[string][]string) map[string]string {
+	ids := make(map[string]string, len(employerCases))
+	for name := range employerCases {
+		ids[domain.EmployerID(name)] = name
+	}
+	return ids
+}
+
+//employerName for an id, an exact employer name is accepted as well
+func (lcaRepo LcaRepo) employerName(id string) (string, bool) {
+	if name, ok := lcaRepo.employerIDs[id]; ok {
+		return name, true
+	}
+	_, ok := lcaRepo.store.EmployerCases[id]
+	return id, ok
+}
+
+//employerCases of a name, each case once
+func (lcaRepo LcaRepo) employerCases(name string) []domain.Lca {
+	var lcas []domain.Lca
+	seen := make(map[string]bool)
+	for _, casenum := range lcaRepo.store.EmployerCases[name] {
+		if !seen[casenum] {
+			seen[casenum] = true
+			lcas = append(lcas, lcaRepo.store.Cases[casenum])
+		}
+	}
+	return lcas
+}
+
+//GetEmployerProfile builds the profile of an employer from all of its cases
+func (lcaRepo LcaRepo) GetEmployerProfile(id string) (domain.EmployerProfile, error) {
+	name, ok := lcaRepo.employerName(id)
+	if !ok {
+		return domain.EmployerProfile{}, domain.ErrNotFound
+	}
+
+	profile := domain.EmployerProfile{Id: domain.EmployerID(name), Name: name}
+	years := make(map[int]*domain.EmployerYear)
+	titlePays := make(map[string][]int)
+	titleFilings := make(map[string]int)
+	zips := make(map[string]int)
+	visas := make(map[string]int)
+	var certified, denied, withdrawn int
+	var feinFiled time.Time
+
+	for _, lca := range lcaRepo.employerCases(name) {
+		profile.Filings++
+		profile.Workers += lca.Total_workers
+
+		switch lca.Case_status {
+		case domain.StatusCertified:
+			certified++
+		case domain.StatusDenied:
+			denied++
+		case domain.StatusWithdrawn, domain.StatusCertifiedWithdrawn:
+			withdrawn++
+		}
+
+		if !lca.Submit_date.IsZero() {
+			if profile.FirstFiled.IsZero() || lca.Submit_date.Before(profile.FirstFiled) {
+				profile.FirstFiled = lca.Submit_date
+			}
+			if lca.Submit_date.After(profile.LastFiled) {
+				profile.LastFiled = lca.Submit_date
+			}
+		}
+
+		//the FEIN of the latest filing that gives one
+		if len(lca.Employer_fein) > 0 && (len(profile.Fein) == 0 || !lca.Submit_date.Before(feinFiled)) {
+			profile.Fein = lca.Employer_fein
+			feinFiled = lca.Submit_date
+		}
+
+		year, ok := years[lca.Year]
+		if !ok {
+			year = &domain.EmployerYear{Year: lca.Year}
+			years[lca.Year] = year
+		}
+		year.Filings++
+		year.Workers += lca.Total_workers
+		year.H1bDependent = year.H1bDependent || lca.H1b_dependent == "Y"
+		year.WillfulViolator = year.WillfulViolator || lca.Willful_voilator == "Y"
+
+		titleFilings[lca.Job_title]++
+		if lca.Pay > 0 {
+			titlePays[lca.Job_title] = append(titlePays[lca.Job_title], lca.Pay)
+		}
+
+		if len(lca.Work_location_zip) > 0 {
+			zips[lca.Work_location_zip]++
+		}
+		visas[lca.VisaClass()]++
+	}
+
+	if profile.Filings > 0 {
+		profile.CertifiedRate = float64(certified) / float64(profile.Filings)
+		profile.DeniedRate = float64(denied) / float64(profile.Filings)
+		profile.WithdrawnRate = float64(withdrawn) / float64(profile.Filings)
+	}
+
+	for _, year := range years {
+		profile.Years = append(profile.Years, *year)
+	}
+	sort.Slice(profile.Years, func(i, j int) bool {
+		return profile.Years[i].Year < profile.Years[j].Year
+	})
+
+	for title, filings := range titleFilings {
+		profile.TopTitles = append(profile.TopTitles, domain.TitlePay{Title: title, Filings: filings, MedianPay: payStats(title, titlePays[title]).P50})
+	}
+	sort.Slice(profile.TopTitles, func(i, j int) bool {
+		if profile.TopTitles[i].Filings != profile.TopTitles[j].Filings {
+			return profile.TopTitles[i].Filings > profile.TopTitles[j].Filings
+		}
+		return profile.TopTitles[i].Title < profile.TopTitles[j].Title
+	})
+	if len(profile.TopTitles) > constTopTitlesCap {
+		profile.TopTitles = profile.TopTitles[:constTopTitlesCap]
+	}
+
+	for zip, n := range zips {
+		profile.WorksiteZips = append(profile.WorksiteZips, domain.FacetCount{Value: zip, Count: n})
+	}
+	sort.Slice(profile.WorksiteZips, func(i, j int) bool {
+		if profile.WorksiteZips[i].Count != profile.WorksiteZips[j].Count {
+			return profile.WorksiteZips[i].Count > profile.WorksiteZips[j].Count
+		}
+		return profile.WorksiteZips[i].Value < profile.WorksiteZips[j].Value
+	})
+	if len(profile.WorksiteZips) > constWorksiteZipsCap {
+		profile.WorksiteZips = profile.WorksiteZips[:constWorksiteZipsCap]
+	}
+
+	for visaClass, n := range visas {
+		profile.VisaClasses = append(profile.VisaClasses, domain.FacetCount{Value: visaClass, Count: n})
+	}
+	sort.Slice(profile.VisaClasses, func(i, j int) bool {
+		if profile.VisaClasses[i].Count != profile.VisaClasses[j].Count {
+			return profile.VisaClasses[i].Count > profile.VisaClasses[j].Count
+		}
+		return profile.VisaClasses[i].Value < profile.VisaClasses[j].Value
+	})
+
+	return profile, nil
+}
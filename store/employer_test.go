@@ -0,0 +1,39 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+func TestGetEmployerProfile(t *testing.T) {
+	submitted := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+	lcaRepo := newTestRepo(
+		domain.Lca{Year: 2018, Case_number: "1", Case_status: "CERTIFIED", Employer_name: "ACME", Employer_zip: "160523", Job_title: "DATA ENGINEER", Pay: 100000, Total_workers: 2, Submit_date: submitted, H1b_dependent: "N"},
+		domain.Lca{Year: 2018, Case_number: "2", Case_status: "DENIED", Employer_name: "ACME", Employer_zip: "160523", Job_title: "DATA ENGINEER", Pay: 120000, Total_workers: 1, Submit_date: submitted.AddDate(0, 1, 0), H1b_dependent: "N"},
+		domain.Lca{Year: 2019, Case_number: "3", Case_status: "CERTIFIED-WITHDRAWN", Employer_name: "ACME", Employer_zip: "160523", Job_title: "ANALYST", Pay: 80000, Total_workers: 1, Submit_date: submitted.AddDate(1, 0, 0), H1b_dependent: "Y"},
+		domain.Lca{Year: 2019, Case_number: "4", Case_status: "CERTIFIED", Employer_name: "INITECH", Employer_zip: "160523"},
+	)
+
+	if _, err := lcaRepo.GetEmployerProfile("nope"); err != domain.ErrNotFound {
+		t.Errorf("got %v; want ErrNotFound", err)
+	}
+
+	profile, err := lcaRepo.GetEmployerProfile(domain.EmployerID("ACME"))
+	if err != nil {
+		t.Fatalf("got %v; want no error", err)
+	}
+	if profile.Filings != 3 || profile.Workers != 4 || profile.DeniedRate != 1.0/3 || profile.WithdrawnRate != 1.0/3 {
+		t.Errorf("got %+v; want 3 filings, 4 workers and a third denied and withdrawn", profile)
+	}
+	if !profile.FirstFiled.Equal(submitted) || !profile.LastFiled.Equal(submitted.AddDate(1, 0, 0)) {
+		t.Errorf("got first %v, last %v; want the earliest and latest submit dates", profile.FirstFiled, profile.LastFiled)
+	}
+	if len(profile.Years) != 2 || profile.Years[0].H1bDependent || !profile.Years[1].H1bDependent {
+		t.Errorf("got %+v; want 2018 not dependent and 2019 dependent", profile.Years)
+	}
+	if profile.TopTitles[0] != (domain.TitlePay{Title: "DATA ENGINEER", Filings: 2, MedianPay: 110000}) {
+		t.Errorf("got %+v; want DATA ENGINEER first with a 110000 median", profile.TopTitles[0])
+	}
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"sort"
+	"strconv"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+const constFacetCap = 20
+
+//facetValue of a case, the year facet goes by the same year mode as the H1Year filter
+func facetValue(lca domain.Lca, facet string, yearMode string) string {
+	switch facet {
+	case domain.FacetEmployer:
+		return lca.Employer_name
+	case domain.FacetYear:
+		year := lca.YearIn(yearMode)
+		if year <= 1 {
+			return ""
+		}
+		return strconv.Itoa(year)
+	case domain.FacetWageLevel:
+		return domain.NormalizeWageLevel(lca.Wage_level)
+	case domain.FacetState:
+		return lca.Work_location_state
+	case domain.FacetStatus:
+		return lca.Case_status
+	case domain.FacetSoc:
+		return lca.Soc_code
+	case domain.FacetVisaClass:
+		return lca.VisaClass()
+	}
+	return ""
+}
+
+//facets counts every found case, not just the page returned, keeping the most common values of each facet
+func (index caseIndex) facets(found []string, searchCriteria domain.SearchCriteria) map[string][]domain.FacetCount {
+	names := searchCriteria.Facets
+	if len(names) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]map[string]int)
+	for _, name := range names {
+		counts[name] = make(map[string]int)
+	}
+
+	for _, casenum := range found {
+		lca := index.cases[casenum]
+		for name, values := range counts {
+			if value := facetValue(lca, name, searchCriteria.YearMode); len(value) > 0 {
+				values[value]++
+			}
+		}
+	}
+
+	facets := make(map[string][]domain.FacetCount)
+	for name, values := range counts {
+		facetCounts := make([]domain.FacetCount, 0, len(values))
+		for value, n := range values {
+			facetCounts = append(facetCounts, domain.FacetCount{Value: value, Count: n})
+		}
+		sort.Slice(facetCounts, func(i, j int) bool {
+			if facetCounts[i].Count != facetCounts[j].Count {
+				return facetCounts[i].Count > facetCounts[j].Count
+			}
+			return facetCounts[i].Value < facetCounts[j].Value
+		})
+		if len(facetCounts) > constFacetCap {
+			facetCounts = facetCounts[:constFacetCap]
+		}
+		facets[name] = facetCounts
+	}
+	return facets
+}
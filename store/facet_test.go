@@ -0,0 +1,24 @@
+package store
+
+import (
+	"testing"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+func TestGetFacets(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Case_status: "CERTIFIED", Work_location_state: "IL"},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "160523", Case_status: "CERTIFIED", Work_location_state: "IL"},
+		domain.Lca{Case_number: "3", Employer_name: "ACME", Employer_zip: "160523", Case_status: "DENIED", Work_location_state: "WI"},
+	)
+
+	it, _ := lcaRepo.Get(domain.SearchCriteria{Employer: "ACME", PageSize: 1, Facets: []string{domain.FacetStatus, domain.FacetState}, AnyCaseStatus: true})
+	facets := it.Facets()
+	if len(facets[domain.FacetStatus]) != 2 || facets[domain.FacetStatus][0] != (domain.FacetCount{Value: "CERTIFIED", Count: 2}) {
+		t.Errorf("got %v; want status counts over every match, most common first", facets[domain.FacetStatus])
+	}
+	if len(facets[domain.FacetState]) != 2 {
+		t.Errorf("got %v; want 2 states", facets[domain.FacetState])
+	}
+}
@@ -0,0 +1,383 @@
+package store
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+//rowReader reads the rows of a data file one at a time, io.EOF after the last
+type rowReader interface {
+	Read() ([]string, error)
+}
+
+//closers close in order, the reader before the file under it
+type closers []io.Closer
+
+func (c closers) Close() error {
+	var err error
+	for _, closer := range c {
+		if e := closer.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+type closeFunc func()
+
+func (f closeFunc) Close() error {
+	f()
+	return nil
+}
+
+//columns finds the fields of a row by the names its file's header gives them
+type columns map[string]int
+
+func newColumns(header []string) columns {
+	c := make(columns)
+	for i, name := range header {
+		c[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	return c
+}
+
+//has tells if the header has any of names
+func (c columns) has(names []string) bool {
+	for _, name := range names {
+		if _, ok := c[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+//field is the value of the first of names the header has
+func (c columns) field(line []string, names []string) string {
+	for _, name := range names {
+		if i, ok := c[name]; ok && i < len(line) {
+			return strings.TrimSpace(line[i])
+		}
+	}
+	return ""
+}
+
+func csvRows(r io.Reader) rowReader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader
+}
+
+//openRows opens a data file by its extension, so DOL downloads can be dropped in as they are:
+//csv or xlsx, a csv compressed with gzip (.csv.gz) or zstd (.csv.zst), or the first csv or xlsx in a zip
+func openRows(name string) (rowReader, io.Closer, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".gz":
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return csvRows(gz), closers{gz, f}, nil
+
+	case ".zst", ".zstd":
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return csvRows(zr), closers{closeFunc(zr.Close), f}, nil
+
+	case ".zip":
+		rows, closer, err := openZippedRows(f)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return rows, closers{closer, f}, nil
+
+	case ".xlsx":
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		rows, closer, err := openXlsxRows(f, info.Size())
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return rows, closers{closer, f}, nil
+	}
+	return csvRows(f), f, nil
+}
+
+//openZippedRows reads the first csv or xlsx of a zip archive
+func openZippedRows(f *os.File) (rowReader, io.Closer, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	archive, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, file := range archive.File {
+		if strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+		switch strings.ToLower(path.Ext(file.Name)) {
+		case ".csv":
+			rc, err := file.Open()
+			if err != nil {
+				return nil, nil, err
+			}
+			return csvRows(rc), rc, nil
+
+		case ".xlsx":
+			//an xlsx is a zip itself and needs random access, so it is read into memory
+			rc, err := file.Open()
+			if err != nil {
+				return nil, nil, err
+			}
+			b, err := ioutil.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return nil, nil, err
+			}
+			return openXlsxRows(bytes.NewReader(b), int64(len(b)))
+		}
+	}
+	return nil, nil, errors.New("no csv or xlsx file in zip archive")
+}
+
+//xlsxRows streams the rows of the first worksheet of an xlsx workbook. Shared strings are
+//looked up and cells formatted as dates are given as 2006-01-02
+type xlsxRows struct {
+	decoder       *xml.Decoder
+	sharedStrings []string
+	dateStyles    []bool
+}
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (text xlsxText) String() string {
+	s := text.T
+	for _, run := range text.Runs {
+		s += run.T
+	}
+	return s
+}
+
+type xlsxCell struct {
+	Ref    string   `xml:"r,attr"`
+	Type   string   `xml:"t,attr"`
+	Style  int      `xml:"s,attr"`
+	Value  string   `xml:"v"`
+	Inline xlsxText `xml:"is"`
+}
+
+type xlsxRow struct {
+	Cells []xlsxCell `xml:"c"`
+}
+
+//excelEpoch is day 0 of xlsx date serials, as counted by spreadsheets with the 1900 leap year bug
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+func openXlsxRows(r io.ReaderAt, size int64) (rowReader, io.Closer, error) {
+	workbook, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make(map[string]*zip.File)
+	for _, file := range workbook.File {
+		files[file.Name] = file
+	}
+
+	rows := &xlsxRows{}
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err = decodeXML(file, &sst); err != nil {
+			return nil, nil, err
+		}
+		for _, item := range sst.Items {
+			rows.sharedStrings = append(rows.sharedStrings, item.String())
+		}
+	}
+	if file, ok := files["xl/styles.xml"]; ok {
+		if rows.dateStyles, err = xlsxDateStyles(file); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	sheet, ok := files[xlsxFirstSheet(files)]
+	if !ok {
+		return nil, nil, errors.New("xlsx workbook has no worksheet")
+	}
+	rc, err := sheet.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	rows.decoder = xml.NewDecoder(rc)
+	return rows, rc, nil
+}
+
+func decodeXML(file *zip.File, v interface{}) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+//xlsxFirstSheet is the path of the workbook's first sheet, sheet1.xml when the workbook does not say
+func xlsxFirstSheet(files map[string]*zip.File) string {
+	fallback := "xl/worksheets/sheet1.xml"
+
+	var book struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	bookFile, ok := files["xl/workbook.xml"]
+	relsFile, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok || !ok2 || decodeXML(bookFile, &book) != nil || decodeXML(relsFile, &rels) != nil || len(book.Sheets) == 0 {
+		return fallback
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID == book.Sheets[0].ID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/")
+			}
+			return path.Join("xl", rel.Target)
+		}
+	}
+	return fallback
+}
+
+//xlsxDateStyles tells by cell style index which styles show numbers as dates
+func xlsxDateStyles(file *zip.File) ([]bool, error) {
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := decodeXML(file, &styles); err != nil {
+		return nil, err
+	}
+
+	//built in date formats, and custom formats with days or years in them
+	dateFormats := map[int]bool{14: true, 15: true, 16: true, 17: true, 18: true, 19: true, 20: true, 21: true, 22: true, 45: true, 46: true, 47: true}
+	for _, numFmt := range styles.NumFmts {
+		code := strings.ToLower(numFmt.Code)
+		dateFormats[numFmt.ID] = strings.Contains(code, "yy") || strings.Contains(code, "d")
+	}
+
+	dateStyles := make([]bool, len(styles.CellXfs))
+	for i, xf := range styles.CellXfs {
+		dateStyles[i] = dateFormats[xf.NumFmtID]
+	}
+	return dateStyles, nil
+}
+
+func (rows *xlsxRows) Read() ([]string, error) {
+	for {
+		token, err := rows.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xlsxRow
+		if err = rows.decoder.DecodeElement(&row, &start); err != nil {
+			return nil, err
+		}
+
+		var values []string
+		for i, cell := range row.Cells {
+			column := xlsxColumn(cell.Ref, i)
+			for len(values) <= column {
+				values = append(values, "")
+			}
+			values[column] = rows.value(cell)
+		}
+		return values, nil
+	}
+}
+
+func (rows *xlsxRows) value(cell xlsxCell) string {
+	switch cell.Type {
+	case "s":
+		i, err := strconv.Atoi(cell.Value)
+		if err != nil || i < 0 || i >= len(rows.sharedStrings) {
+			return ""
+		}
+		return rows.sharedStrings[i]
+	case "inlineStr":
+		return cell.Inline.String()
+	case "", "n":
+		if cell.Style < len(rows.dateStyles) && rows.dateStyles[cell.Style] {
+			if serial, err := strconv.ParseFloat(cell.Value, 64); err == nil {
+				return excelEpoch.AddDate(0, 0, int(math.Floor(serial))).Format("2006-01-02")
+			}
+		}
+	}
+	return cell.Value
+}
+
+//xlsxColumn is the zero based column of a cell reference like AB12, i when the cell has no reference
+func xlsxColumn(ref string, i int) int {
+	column := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		column = column*26 + int(c-'A') + 1
+	}
+	if column == 0 {
+		return i
+	}
+	return column - 1
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+//readRows reads every row of a data file as comma joined lines
+func readRows(t *testing.T, name string) []string {
+	rows, closer, err := openRows(name)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	defer closer.Close()
+
+	var lines []string
+	for {
+		row, err := rows.Read()
+		if err == io.EOF {
+			return lines
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		lines = append(lines, strings.Join(row, ","))
+	}
+}
+
+func TestOpenRows(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	csv := "CASE_NUMBER,EMPLOYER_NAME\nI-1,\"Acme, Inc\"\n"
+	want := "CASE_NUMBER,EMPLOYER_NAME|I-1,Acme, Inc"
+
+	gzFile := filepath.Join(dir, "2020.csv.gz")
+	f, _ := os.Create(gzFile)
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte(csv))
+	gz.Close()
+	f.Close()
+
+	zipFile := filepath.Join(dir, "2020.zip")
+	f, _ = os.Create(zipFile)
+	archive := zip.NewWriter(f)
+	w, _ := archive.Create("LCA_Disclosure_Data_FY2020.csv")
+	w.Write([]byte(csv))
+	archive.Close()
+	f.Close()
+
+	zstFile := filepath.Join(dir, "2020.csv.zst")
+	f, _ = os.Create(zstFile)
+	zw, _ := zstd.NewWriter(f)
+	zw.Write([]byte(csv))
+	zw.Close()
+	f.Close()
+
+	for _, name := range []string{gzFile, zipFile, zstFile} {
+		if got := strings.Join(readRows(t, name), "|"); got != want {
+			t.Errorf("%s: got %q; want %q", name, got, want)
+		}
+	}
+}
+
+func TestOpenXlsxRows(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	xlsxFile := filepath.Join(dir, "2020.xlsx")
+	f, _ := os.Create(xlsxFile)
+	archive := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"xl/sharedStrings.xml":     `<sst><si><t>CASE_NUMBER</t></si><si><t>DECISION_DATE</t></si><si><r><t>I-</t></r><r><t>1</t></r></si></sst>`,
+		"xl/styles.xml":            `<styleSheet><cellXfs><xf numFmtId="0"/><xf numFmtId="14"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row><row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2" s="1"><v>43845</v></c></row></sheetData></worksheet>`,
+	} {
+		w, _ := archive.Create(name)
+		w.Write([]byte(content))
+	}
+	archive.Close()
+	f.Close()
+
+	//shared strings looked up, the skipped column B left empty and the date serial formatted
+	want := "CASE_NUMBER,,DECISION_DATE|I-1,,2020-01-15"
+	if got := strings.Join(readRows(t, xlsxFile), "|"); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+const (
+	constComparablesCap  = 5
+	constComparableRange = 0.1
+)
+
+//cohortFilter matches cases with the same normalized job title or SOC code
+type cohortFilter struct {
+	title   string
+	socCode string
+}
+
+func (f cohortFilter) Match(lca domain.Lca) bool {
+	return (len(f.title) > 0 && domain.NormalizeTitle(lca.Job_title) == f.title) ||
+		(len(f.socCode) > 0 && strings.HasPrefix(lca.Soc_code, f.socCode))
+}
+
+//dolWageLevel an offer at percentile p of the cohort would meet
+func dolWageLevel(p float64) string {
+	switch {
+	case p >= 67:
+		return "IV"
+	case p >= 50:
+		return "III"
+	case p >= 34:
+		return "II"
+	case p >= 17:
+		return "I"
+	}
+	return "below I"
+}
+
+//EvaluateOffer ranks an offer among comparable cases with a yearly pay
+func (lcaRepo LcaRepo) EvaluateOffer(offer domain.Offer) (domain.OfferEvaluation, error) {
+	evaluation := domain.OfferEvaluation{Pay: offer.Pay, YearMode: offer.YearMode}
+
+	searchCriteria := domain.SearchCriteria{Zipcode: offer.Zipcode, Radius: offer.Radius,
+		Query: cohortFilter{title: domain.NormalizeTitle(offer.JobTitle), socCode: strings.TrimSpace(offer.SocCode)}}
+
+	var cohort []domain.Lca
+	latestYear := 0
+	for _, casenum := range lcaRepo.search(searchCriteria) {
+		lca := lcaRepo.store.Cases[casenum]
+		if lca.Pay <= 0 {
+			continue
+		}
+		cohort = append(cohort, lca)
+		if lca.YearIn(offer.YearMode) > latestYear {
+			latestYear = lca.YearIn(offer.YearMode)
+		}
+	}
+
+	//recent is relative to the newest data, not today
+	if offer.Years > 0 {
+		recent := cohort[:0]
+		for _, lca := range cohort {
+			if lca.YearIn(offer.YearMode) > latestYear-offer.Years {
+				recent = append(recent, lca)
+			}
+		}
+		cohort = recent
+	}
+
+	evaluation.CohortSize = len(cohort)
+	if len(cohort) == 0 {
+		return evaluation, nil
+	}
+
+	pays := make([]int, len(cohort))
+	below, equal := 0, 0
+	for i, lca := range cohort {
+		pays[i] = lca.Pay
+		if lca.Pay < offer.Pay {
+			below++
+		} else if lca.Pay == offer.Pay {
+			equal++
+		}
+	}
+	evaluation.Cohort = payStats("", pays)
+	evaluation.Percentile = math.Round((float64(below)+float64(equal)/2)/float64(len(cohort))*1000) / 10
+	evaluation.WageLevel = dolWageLevel(evaluation.Percentile)
+
+	//closest pays within 10% of the offer, or the closest overall when too few are that close
+	min := int(float64(offer.Pay) * (1 - constComparableRange))
+	max := int(float64(offer.Pay) * (1 + constComparableRange))
+	var comparables []domain.Lca
+	for _, lca := range cohort {
+		if lca.PayBetween(min, max) {
+			comparables = append(comparables, lca)
+		}
+	}
+	if len(comparables) < constComparablesCap {
+		comparables = cohort
+	}
+	sort.SliceStable(comparables, func(i, j int) bool {
+		di, dj := comparables[i].Pay-offer.Pay, comparables[j].Pay-offer.Pay
+		if di < 0 {
+			di = -di
+		}
+		if dj < 0 {
+			dj = -dj
+		}
+		if di != dj {
+			return di < dj
+		}
+		return comparables[i].Case_number < comparables[j].Case_number
+	})
+	if len(comparables) > constComparablesCap {
+		comparables = comparables[:constComparablesCap]
+	}
+	evaluation.Comparables = comparables
+
+	return evaluation, nil
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+func TestEvaluateOffer(t *testing.T) {
+	var lcas []domain.Lca
+	for i := 1; i <= 10; i++ {
+		lcas = append(lcas, domain.Lca{Case_number: strconv.Itoa(i), Year: 2019, Employer_name: "ACME", Employer_zip: "198101",
+			Job_title: "SR. SOFTWARE ENGR", Pay: 100000 + i*10000, Start_date: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)})
+	}
+	//too old and too far away to be comparable, the old one was filed in a recent fiscal year
+	lcas = append(lcas,
+		domain.Lca{Case_number: "11", Year: 2019, Employer_name: "ACME", Employer_zip: "198101", Job_title: "SENIOR SOFTWARE ENGINEER", Pay: 50000, Start_date: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)},
+		domain.Lca{Case_number: "12", Employer_name: "ACME", Employer_zip: "110001", Job_title: "SENIOR SOFTWARE ENGINEER", Pay: 50000, Start_date: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+	)
+	lcaRepo := newTestRepo(lcas...)
+	lcaRepo.store.ZipcodesNearBy[19810100] = []int{198101}
+
+	evaluation, _ := lcaRepo.EvaluateOffer(domain.Offer{JobTitle: "Senior Software Engineer", Pay: 185000, Zipcode: "98101", Radius: 25, Years: 3})
+	if evaluation.CohortSize != 10 {
+		t.Fatalf("got cohort of %d; want 10", evaluation.CohortSize)
+	}
+	if evaluation.Percentile != 80 || evaluation.WageLevel != "IV" {
+		t.Errorf("got %v percentile at level %s; want 80 at IV", evaluation.Percentile, evaluation.WageLevel)
+	}
+	if len(evaluation.Comparables) != 5 || evaluation.Comparables[0].Pay != 180000 {
+		t.Errorf("got %v; want the 5 closest pays starting at 180000", evaluation.Comparables)
+	}
+
+	evaluation, _ = lcaRepo.EvaluateOffer(domain.Offer{JobTitle: "Senior Software Engineer", Pay: 185000, Zipcode: "98101", Radius: 25, Years: 3,
+		YearMode: domain.YearModeFiscal})
+	if evaluation.CohortSize != 11 || evaluation.YearMode != domain.YearModeFiscal {
+		t.Errorf("got cohort of %d in %q years; want 11 counting fiscal years", evaluation.CohortSize, evaluation.YearMode)
+	}
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+//lcaIterator reads cases of one page lazily, so callers can stream them
+type lcaIterator struct {
+	cases      map[string]domain.Lca
+	page       []string
+	pos        int
+	lca        domain.Lca
+	total      int
+	pageSize   int
+	nextCursor string
+	facets     map[string][]domain.FacetCount
+}
+
+func (it *lcaIterator) Next() bool {
+	if it.pos >= len(it.page) {
+		return false
+	}
+	it.lca = it.cases[it.page[it.pos]]
+	it.pos++
+	return true
+}
+
+func (it *lcaIterator) Lca() domain.Lca {
+	return it.lca
+}
+
+func (it *lcaIterator) Total() int {
+	return it.total
+}
+
+func (it *lcaIterator) PageSize() int {
+	return it.pageSize
+}
+
+func (it *lcaIterator) NextCursor() string {
+	return it.nextCursor
+}
+
+func (it *lcaIterator) Facets() map[string][]domain.FacetCount {
+	return it.facets
+}
+
+//paginate cuts the page the cursor points at out of the ordered case numbers.
+//A cursor carries the offset of the page and the case number right before it,
+//so a cursor from a different search is rejected rather than silently misread
+func paginate(found []string, pageSize int, cursor string) (page []string, size int, nextCursor string, err error) {
+	size = pageSize
+	if size <= 0 {
+		size = constDefaultPageSize
+	}
+	if size > constLcaResponseCap {
+		size = constLcaResponseCap
+	}
+
+	offset := 0
+	if len(cursor) > 0 {
+		offset, err = decodeCursor(found, cursor)
+		if err != nil {
+			return nil, size, "", err
+		}
+	}
+
+	end := offset + size
+	if end >= len(found) {
+		return found[offset:], size, "", nil
+	}
+	return found[offset:end], size, encodeCursor(end, found[end-1]), nil
+}
+
+func encodeCursor(offset int, casenum string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset) + ":" + casenum))
+}
+
+func decodeCursor(found []string, cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, domain.ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(b), ":", 2)
+	if len(parts) != 2 {
+		return 0, domain.ErrInvalidCursor
+	}
+
+	offset, err := strconv.Atoi(parts[0])
+	if err != nil || offset <= 0 || offset > len(found) || found[offset-1] != parts[1] {
+		return 0, domain.ErrInvalidCursor
+	}
+	return offset, nil
+}
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"strings"
+	"testing"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+func TestGetPages(t *testing.T) {
+	var lcas []domain.Lca
+	for _, casenum := range []string{"5", "3", "1", "4", "2"} {
+		lcas = append(lcas, domain.Lca{Case_number: casenum, Employer_name: "ACME", Employer_zip: "160523"})
+	}
+	lcaRepo := newTestRepo(lcas...)
+
+	var got []string
+	searchCriteria := domain.SearchCriteria{Employer: "ACME", PageSize: 2}
+	for {
+		it, err := lcaRepo.Get(searchCriteria)
+		if err != nil {
+			t.Fatalf("got %v; want no error", err)
+		}
+		if it.Total() != 5 || it.PageSize() != 2 {
+			t.Errorf("got total %d, page size %d; want 5, 2", it.Total(), it.PageSize())
+		}
+		for _, lca := range collect(it) {
+			got = append(got, lca.Case_number)
+		}
+		if len(it.NextCursor()) == 0 {
+			break
+		}
+		searchCriteria.Cursor = it.NextCursor()
+	}
+
+	if strings.Join(got, ",") != "1,2,3,4,5" {
+		t.Errorf("got %v; want every case once in case number order", got)
+	}
+
+	_, err := lcaRepo.Get(domain.SearchCriteria{Employer: "ACME", Cursor: "bm90LWEtY3Vyc29y"})
+	if err != domain.ErrInvalidCursor {
+		t.Errorf("got %v; want ErrInvalidCursor", err)
+	}
+}
@@ -0,0 +1,397 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+	log "github.com/kk3399/empnearme/log"
+)
+
+//PermRepo - PERM cases, indexed like LCAs and sharing their table of nearby zipcodes
+type PermRepo struct {
+	store          permStore
+	zipcodesNearBy map[int][]int
+	log            log.Writer
+}
+
+type permStore struct {
+	Cases         map[string]domain.Perm
+	Views         map[string]domain.Lca
+	EmployerCases map[string][]string
+	ZipcodeCases  map[int][]string
+	StateCases    map[string][]string
+	NameCases     map[string][]string
+	FeinCases     map[string][]string
+}
+
+const constPermOccupationsCap = 10
+
+//permColumns are the DOL PERM disclosure headers each field is read from, files of different years name some differently
+var permColumns = map[string][]string{
+	"case_number":            {"CASE_NUMBER", "CASE_NO"},
+	"case_status":            {"CASE_STATUS"},
+	"received_date":          {"CASE_RECEIVED_DATE", "RECEIVED_DATE"},
+	"decision_date":          {"DECISION_DATE"},
+	"employer_name":          {"EMPLOYER_NAME"},
+	"employer_address":       {"EMPLOYER_ADDRESS_1", "EMPLOYER_ADDRESS1"},
+	"employer_city":          {"EMPLOYER_CITY"},
+	"employer_state":         {"EMPLOYER_STATE", "EMPLOYER_STATE_PROVINCE"},
+	"employer_zip":           {"EMPLOYER_POSTAL_CODE"},
+	"employer_fein":          {"EMPLOYER_FEIN", "EMP_FEIN", "FEIN"},
+	"job_title":              {"JOB_INFO_JOB_TITLE", "JOB_TITLE"},
+	"soc_code":               {"PW_SOC_CODE"},
+	"soc_name":               {"PW_SOC_TITLE"},
+	"naics_code":             {"NAICS_US_CODE", "NAICS_CODE", "NAICS_US_CODE_2007"},
+	"wage_offer_from":        {"WAGE_OFFER_FROM_9089", "WAGE_OFFERED_FROM_9089", "JOB_OPP_WAGE_FROM"},
+	"wage_offer_to":          {"WAGE_OFFER_TO_9089", "WAGE_OFFERED_TO_9089", "JOB_OPP_WAGE_TO"},
+	"wage_unit":              {"WAGE_OFFER_UNIT_OF_PAY_9089", "WAGE_OFFERED_UNIT_OF_PAY_9089", "JOB_OPP_WAGE_PER"},
+	"pw_level":               {"PW_LEVEL_9089", "PW_SKILL_LEVEL"},
+	"class_of_admission":     {"CLASS_OF_ADMISSION"},
+	"country_of_citizenship": {"COUNTRY_OF_CITIZENSHIP", "COUNTRY_OF_CITZENSHIP"},
+	"work_location_city":     {"JOB_INFO_WORK_CITY", "WORKSITE_CITY"},
+	"work_location_state":    {"JOB_INFO_WORK_STATE", "WORKSITE_STATE"},
+	"work_location_zip":      {"JOB_INFO_WORK_POSTAL_CODE", "WORKSITE_POSTAL_CODE"},
+}
+
+//date layouts of DOL disclosure files, xlsx dates are read as 2006-01-02
+var dolDateLayouts = []string{"2006-01-02", "1/2/2006", "2006-01-02 15:04:05", "1/2/2006 15:04"}
+
+//yearly pay multipliers of the DOL units of pay
+var payPeriodsPerYear = map[string]float64{
+	"YEAR":      1,
+	"YR":        1,
+	"MONTH":     12,
+	"MTH":       12,
+	"BI-WEEKLY": 26,
+	"BI":        26,
+	"WEEK":      52,
+	"WK":        52,
+	"HOUR":      2080,
+	"HR":        2080,
+}
+
+//InitPerm loads the PERM disclosure files of the manifest, corrected by the corrections files
+//Init read for the LCA cases
+func InitPerm(log log.Writer, lcaRepo LcaRepo, manifest Manifest) PermRepo {
+	permRepo := PermRepo{log: log, zipcodesNearBy: lcaRepo.store.ZipcodesNearBy}
+	permRepo.store = permStore{
+		Cases:         make(map[string]domain.Perm),
+		Views:         make(map[string]domain.Lca),
+		EmployerCases: make(map[string][]string),
+		ZipcodeCases:  make(map[int][]string),
+		StateCases:    make(map[string][]string),
+		NameCases:     make(map[string][]string),
+		FeinCases:     make(map[string][]string),
+	}
+
+	manifest.load(SourcePerm, log, func(record interface{}) {
+		perm := record.(domain.Perm)
+		lcaRepo.corrections.applyPerm(&perm, log)
+		permRepo.add(perm)
+	})
+
+	zipcodes := make(map[int]bool)
+	for zipcode := range permRepo.store.ZipcodeCases {
+		zipcodes[zipcode] = true
+	}
+	addZipcodesNearBy(permRepo.zipcodesNearBy, zipcodes)
+
+	log.Info(fmt.Sprintf("perm cases: %d", len(permRepo.store.Cases)))
+	return permRepo
+}
+
+//permSource reads a PERM disclosure file of one fiscal year
+type permSource struct {
+	path string
+	year int
+	log  log.Writer
+}
+
+func (source permSource) Load(add func(record interface{})) error {
+	rows, closer, err := openRows(source.path)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	return source.read(rows, add)
+}
+
+//read a PERM disclosure file by its header, cases without a case number are skipped
+func (source permSource) read(rows rowReader, add func(record interface{})) error {
+	first, err := rows.Read()
+	if err != nil {
+		return err
+	}
+	header := newColumns(first)
+
+	for {
+		line, err := rows.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		field := func(name string) string {
+			return header.field(line, permColumns[name])
+		}
+
+		perm := domain.Perm{Year: source.year}
+		perm.Case_number = field("case_number")
+		if len(perm.Case_number) == 0 {
+			continue
+		}
+		perm.Case_status = strings.ToUpper(field("case_status"))
+		perm.Received_date = parseDolDate(field("received_date"), source.log)
+		perm.Decision_date = parseDolDate(field("decision_date"), source.log)
+		perm.Employer_name = strings.ToUpper(field("employer_name"))
+		perm.Employer_address = field("employer_address")
+		perm.Employer_city = field("employer_city")
+		perm.Employer_state = field("employer_state")
+		perm.Employer_zip = "1" + fmt.Sprintf("%05s", firstN(field("employer_zip"), 5))
+		perm.Employer_fein = field("employer_fein")
+		perm.Job_title = strings.ToUpper(field("job_title"))
+		perm.Soc_code = field("soc_code")
+		perm.Soc_name = field("soc_name")
+		perm.Naics_code = field("naics_code")
+		perm.Wage_offer_from = field("wage_offer_from")
+		perm.Wage_offer_to = field("wage_offer_to")
+		perm.Wage_unit = field("wage_unit")
+		perm.Pay = yearlyPay(perm.Wage_offer_from, perm.Wage_unit)
+		perm.Pw_level = field("pw_level")
+		perm.Class_of_admission = field("class_of_admission")
+		perm.Country_of_citizenship = field("country_of_citizenship")
+		perm.Work_location_city = field("work_location_city")
+		perm.Work_location_state = field("work_location_state")
+		perm.Work_location_zip = firstN(field("work_location_zip"), 5)
+
+		add(perm)
+	}
+}
+
+//parseDolDate parses a date of a DOL disclosure file, the zero time when there is none
+func parseDolDate(dt string, log log.Writer) time.Time {
+	if len(dt) == 0 {
+		return time.Time{}
+	}
+	for _, layout := range dolDateLayouts {
+		if t, err := time.Parse(layout, dt); err == nil {
+			return t
+		}
+	}
+	log.Error("unknown date " + dt)
+	return time.Time{}
+}
+
+func firstN(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+//yearlyPay of a wage in any unit of pay, 0 when the wage or unit is unknown
+func yearlyPay(wage string, unit string) int {
+	perYear, ok := payPeriodsPerYear[strings.ToUpper(strings.TrimSpace(unit))]
+	if !ok {
+		return 0
+	}
+	pay, err := strconv.ParseFloat(strings.NewReplacer("$", "", ",", "").Replace(strings.TrimSpace(wage)), 64)
+	if err != nil {
+		return 0
+	}
+	return int(pay * perYear)
+}
+
+func (permRepo PermRepo) add(perm domain.Perm) {
+	permRepo.store.Cases[perm.Case_number] = perm
+	view := perm.AsLca()
+	permRepo.store.Views[perm.Case_number] = view
+
+	permRepo.store.EmployerCases[perm.Employer_name] = append(permRepo.store.EmployerCases[perm.Employer_name], perm.Case_number)
+
+	zipcodeKey, _ := strconv.Atoi(perm.Employer_zip)
+	permRepo.store.ZipcodeCases[zipcodeKey] = append(permRepo.store.ZipcodeCases[zipcodeKey], perm.Case_number)
+
+	for _, state := range caseStates(view) {
+		permRepo.store.StateCases[state] = append(permRepo.store.StateCases[state], perm.Case_number)
+	}
+
+	if name := domain.NormalizeEmployerName(perm.Employer_name); len(name) > 0 {
+		permRepo.store.NameCases[name] = append(permRepo.store.NameCases[name], perm.Case_number)
+	}
+	if fein := domain.NormalizeFein(perm.Employer_fein); len(fein) > 0 {
+		permRepo.store.FeinCases[fein] = append(permRepo.store.FeinCases[fein], perm.Case_number)
+	}
+}
+
+//GetEmployerHistory sums up the PERM filings of an LCA employer. A FEIN known on both sides decides,
+//otherwise normalized names have to match, so employers sharing a name but not a FEIN stay apart
+func (permRepo PermRepo) GetEmployerHistory(name string, fein string) domain.PermHistory {
+	name = domain.NormalizeEmployerName(name)
+	fein = domain.NormalizeFein(fein)
+
+	var history domain.PermHistory
+	var certified int
+	occupations := make(map[string]int)
+	seen := make(map[string]bool)
+
+	for _, casenums := range [][]string{permRepo.store.FeinCases[fein], permRepo.store.NameCases[name]} {
+		for _, casenum := range casenums {
+			if seen[casenum] {
+				continue
+			}
+			seen[casenum] = true
+
+			perm := permRepo.store.Cases[casenum]
+			permFein := domain.NormalizeFein(perm.Employer_fein)
+			if len(fein) > 0 && len(permFein) > 0 {
+				if fein != permFein {
+					continue
+				}
+			} else if len(name) == 0 || domain.NormalizeEmployerName(perm.Employer_name) != name {
+				continue
+			}
+
+			history.Filings++
+			if perm.Case_status == domain.StatusCertified || perm.Case_status == domain.StatusCertifiedExpired {
+				certified++
+			}
+			occupation := perm.Soc_name
+			if len(occupation) == 0 {
+				occupation = perm.Job_title
+			}
+			if len(occupation) > 0 {
+				occupations[occupation]++
+			}
+		}
+	}
+
+	if history.Filings > 0 {
+		history.CertifiedRate = float64(certified) / float64(history.Filings)
+	}
+	for occupation, n := range occupations {
+		history.Occupations = append(history.Occupations, domain.FacetCount{Value: occupation, Count: n})
+	}
+	sort.Slice(history.Occupations, func(i, j int) bool {
+		if history.Occupations[i].Count != history.Occupations[j].Count {
+			return history.Occupations[i].Count > history.Occupations[j].Count
+		}
+		return history.Occupations[i].Value < history.Occupations[j].Value
+	})
+	if len(history.Occupations) > constPermOccupationsCap {
+		history.Occupations = history.Occupations[:constPermOccupationsCap]
+	}
+	return history
+}
+
+//addZipcodesNearBy adds the zipcodes the table of nearby zipcodes does not know yet, so
+//employers in zipcodes no LCA employer is in can still be searched by distance
+func addZipcodesNearBy(zipcodesNearBy map[int][]int, zipcodes map[int]bool) {
+	loadZipCodesIfNeeded()
+
+	known := make(map[int]bool)
+	for zipkey := range zipcodesNearBy {
+		known[zipkey/100] = true
+	}
+
+	added := make(map[int]bool)
+	for zipcode := range zipcodes {
+		if _, ok := zipcodeMap[zipcode]; ok && !known[zipcode] {
+			added[zipcode] = true
+			known[zipcode] = true
+		}
+	}
+
+	for zipcodeFrom := range added {
+		geoCoordFrom := zipcodeMap[zipcodeFrom]
+		for zipcodeTo := range known {
+			geoCoordTo, ok := zipcodeMap[zipcodeTo]
+			if !ok {
+				continue
+			}
+			miles := getDistance(geoCoordFrom.lat, geoCoordFrom.long, geoCoordTo.lat, geoCoordTo.long)
+			if miles >= constMaxRadiusInMiles {
+				continue
+			}
+			band := int(miles / 5)
+			zipcodesNearBy[zipcodeFrom*100+band] = append(zipcodesNearBy[zipcodeFrom*100+band], zipcodeTo)
+			//pairs of added zipcodes are seen from both ends
+			if !added[zipcodeTo] {
+				zipcodesNearBy[zipcodeTo*100+band] = append(zipcodesNearBy[zipcodeTo*100+band], zipcodeFrom)
+			}
+		}
+	}
+}
+
+func (permRepo PermRepo) index() caseIndex {
+	return caseIndex{certified: []string{domain.StatusCertified, domain.StatusCertifiedExpired}, cases: permRepo.store.Views, employerCases: permRepo.store.EmployerCases, zipcodeCases: permRepo.store.ZipcodeCases,
+		stateCases: permRepo.store.StateCases, zipcodesNearBy: permRepo.zipcodesNearBy, log: permRepo.log}
+}
+
+//Get PERM cases, one page at a time in the criteria's sort order
+func (permRepo PermRepo) Get(searchCriteria domain.SearchCriteria) (domain.PermIterator, error) {
+	index := permRepo.index()
+	found := index.search(searchCriteria)
+	index.order(found, searchCriteria)
+
+	page, size, nextCursor, err := paginate(found, searchCriteria.PageSize, searchCriteria.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &permIterator{cases: permRepo.store.Cases, page: page, total: len(found), pageSize: size, nextCursor: nextCursor,
+		facets: index.facets(found, searchCriteria)}, nil
+}
+
+//GetPayStats of PERM cases matching the criteria, by the offered wage
+func (permRepo PermRepo) GetPayStats(searchCriteria domain.SearchCriteria, groupBy string) ([]domain.PayStats, error) {
+	return permRepo.index().payStatsBy(searchCriteria, groupBy), nil
+}
+
+//permIterator reads PERM cases of one page lazily
+type permIterator struct {
+	cases      map[string]domain.Perm
+	page       []string
+	pos        int
+	perm       domain.Perm
+	total      int
+	pageSize   int
+	nextCursor string
+	facets     map[string][]domain.FacetCount
+}
+
+func (it *permIterator) Next() bool {
+	if it.pos >= len(it.page) {
+		return false
+	}
+	it.perm = it.cases[it.page[it.pos]]
+	it.pos++
+	return true
+}
+
+func (it *permIterator) Perm() domain.Perm {
+	return it.perm
+}
+
+func (it *permIterator) Total() int {
+	return it.total
+}
+
+func (it *permIterator) PageSize() int {
+	return it.pageSize
+}
+
+func (it *permIterator) NextCursor() string {
+	return it.nextCursor
+}
+
+func (it *permIterator) Facets() map[string][]domain.FacetCount {
+	return it.facets
+}
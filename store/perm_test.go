@@ -0,0 +1,110 @@
+package store
+
+import (
+	"strings"
+	"testing"
+
+	domain "github.com/kk3399/empnearme/domain"
+	log "github.com/kk3399/empnearme/log"
+)
+
+func newTestPermRepo(lcaRepo LcaRepo) PermRepo {
+	return PermRepo{log: log.Writer{}, zipcodesNearBy: lcaRepo.store.ZipcodesNearBy, store: permStore{
+		Cases:         make(map[string]domain.Perm),
+		Views:         make(map[string]domain.Lca),
+		EmployerCases: make(map[string][]string),
+		ZipcodeCases:  make(map[int][]string),
+		StateCases:    make(map[string][]string),
+		NameCases:     make(map[string][]string),
+		FeinCases:     make(map[string][]string),
+	}}
+}
+
+func TestPermGet(t *testing.T) {
+	lcaRepo := newTestRepo()
+	lcaRepo.store.ZipcodesNearBy[16052300] = []int{160523}
+
+	permRepo := newTestPermRepo(lcaRepo)
+
+	csv := `CASE_NUMBER,CASE_STATUS,RECEIVED_DATE,DECISION_DATE,EMPLOYER_NAME,EMPLOYER_STATE,EMPLOYER_POSTAL_CODE,JOB_TITLE,PW_SOC_CODE,WAGE_OFFER_FROM_9089,WAGE_OFFER_UNIT_OF_PAY_9089,PW_LEVEL_9089
+A-1,Certified,2019-10-01,2020-03-02,Acme Inc,IL,60523-1234,Software Engineer,15-1252,"150,000",Year,Level II
+A-2,Denied,2019-11-01,2020-04-02,ACME INC,IL,60523,Data Analyst,15-2041,50,Hour,Level I
+A-3,Certified,2019-12-01,2020-05-02,INITECH,TX,75001,Engineer,17-2071,120000,Year,Level III
+`
+	source := permSource{year: 2020, log: log.Writer{}}
+	if err := source.read(csvRows(strings.NewReader(csv)), func(record interface{}) { permRepo.add(record.(domain.Perm)) }); err != nil {
+		t.Fatalf("got %v; want no error", err)
+	}
+
+	perm := permRepo.store.Cases["A-2"]
+	if perm.Pay != 104000 || perm.Employer_zip != "160523" || perm.Case_status != domain.StatusDenied {
+		t.Errorf("got %+v; want hourly pay made yearly and the zipcode keyed", perm)
+	}
+
+	it, _ := permRepo.Get(domain.SearchCriteria{Zipcode: "60523", AnyCaseStatus: true, SortBy: domain.SortByPay})
+	var got []string
+	for it.Next() {
+		got = append(got, it.Perm().Case_number)
+	}
+	if strings.Join(got, ",") != "A-2,A-1" {
+		t.Errorf("got %v; want the ACME cases by pay", got)
+	}
+
+	for _, test := range []struct {
+		searchCriteria domain.SearchCriteria
+		want           int
+	}{
+		{domain.SearchCriteria{Employer: "ACME INC"}, 1},
+		{domain.SearchCriteria{State: "TX", WageLevels: []string{"III"}}, 1},
+		{domain.SearchCriteria{Employer: "ACME INC", AnyCaseStatus: true, SocCodes: []string{"15-0000"}, H1Year: 2020, YearMode: domain.YearModeFiscal}, 2},
+	} {
+		it, _ := permRepo.Get(test.searchCriteria)
+		if it.Total() != test.want {
+			t.Errorf("%+v: got %d cases; want %d", test.searchCriteria, it.Total(), test.want)
+		}
+	}
+}
+
+func TestPermEmployerHistory(t *testing.T) {
+	permRepo := newTestPermRepo(newTestRepo())
+	for _, perm := range []domain.Perm{
+		{Case_number: "A-1", Case_status: domain.StatusCertified, Employer_name: "ACME, INC.", Employer_fein: "12-3456789", Soc_name: "Software Developers"},
+		{Case_number: "A-2", Case_status: domain.StatusDenied, Employer_name: "ACME INC", Soc_name: "Software Developers"},
+		{Case_number: "A-3", Case_status: domain.StatusCertifiedExpired, Employer_name: "ACME HOLDINGS LLC", Employer_fein: "123456789", Soc_name: "Statisticians"},
+		{Case_number: "A-4", Case_status: domain.StatusCertified, Employer_name: "ACME INC", Employer_fein: "98-7654321", Soc_name: "Statisticians"},
+	} {
+		permRepo.add(perm)
+	}
+
+	history := permRepo.GetEmployerHistory("ACME INC", "123456789")
+	if history.Filings != 3 || history.CertifiedRate < 0.66 || history.CertifiedRate > 0.67 {
+		t.Errorf("got %+v; want the FEIN matches and the name match without a FEIN", history)
+	}
+	if len(history.Occupations) != 2 || history.Occupations[0] != (domain.FacetCount{Value: "Software Developers", Count: 2}) {
+		t.Errorf("got %v; want occupations by filings", history.Occupations)
+	}
+
+	if history := permRepo.GetEmployerHistory("Acme Inc.", ""); history.Filings != 3 {
+		t.Errorf("got %+v; want every ACME INC filing when the LCA has no FEIN", history)
+	}
+	if history := permRepo.GetEmployerHistory("INITECH", ""); history.Filings != 0 || history.Occupations != nil {
+		t.Errorf("got %+v; want no filings", history)
+	}
+}
+
+func TestPermGetCertifiedExpired(t *testing.T) {
+	permRepo := newTestPermRepo(newTestRepo())
+	for _, perm := range []domain.Perm{
+		{Case_number: "A-1", Case_status: domain.StatusCertified, Employer_name: "ACME INC"},
+		{Case_number: "A-2", Case_status: domain.StatusCertifiedExpired, Employer_name: "ACME INC"},
+		{Case_number: "A-3", Case_status: domain.StatusDenied, Employer_name: "ACME INC"},
+	} {
+		permRepo.add(perm)
+	}
+
+	it, _ := permRepo.Get(domain.SearchCriteria{Employer: "ACME INC"})
+	history := permRepo.GetEmployerHistory("ACME INC", "")
+	if certified := int(history.CertifiedRate*float64(history.Filings) + 0.5); it.Total() != 2 || certified != it.Total() {
+		t.Errorf("got %d cases, %d certified in the history; want expired certifications counted both ways", it.Total(), certified)
+	}
+}
@@ -0,0 +1,232 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	domain "github.com/kk3399/empnearme/domain"
+	log "github.com/kk3399/empnearme/log"
+)
+
+//caseIndex is what a search reads, the cases of one record type seen through their LCA fields
+//and the indexes over them. Every record type shares the one table of nearby zipcodes, certified
+//are the statuses a search returns by default
+type caseIndex struct {
+	certified      []string
+	cases          map[string]domain.Lca
+	employerCases  map[string][]string
+	zipcodeCases   map[int][]string
+	stateCases     map[string][]string
+	zipcodesNearBy map[int][]int
+	log            log.Writer
+}
+
+func (lcaRepo LcaRepo) index() caseIndex {
+	return caseIndex{certified: []string{domain.StatusCertified}, cases: lcaRepo.store.Cases, employerCases: lcaRepo.store.EmployerCases, zipcodeCases: lcaRepo.store.ZipcodeCases,
+		stateCases: lcaRepo.store.StateCases, zipcodesNearBy: lcaRepo.store.ZipcodesNearBy, log: lcaRepo.log}
+}
+
+//plan for one search - every index the criteria can use contributes a candidate list,
+//the smallest one drives the scan and each candidate is checked against every criterion
+type plan struct {
+	criteria   domain.SearchCriteria
+	zipcodes   map[int]bool
+	statuses   map[string]bool
+	levels     map[string]bool
+	visas      map[string]bool
+	codes      map[string][]string
+	candidates [][]string
+
+	state string
+
+	filterEmployer, filterPay, filterH1Year, filterJobTitle, filterState bool
+}
+
+func (index caseIndex) newPlan(searchCriteria domain.SearchCriteria) plan {
+	p := plan{criteria: searchCriteria}
+
+	p.filterEmployer = len(searchCriteria.Employer) > 0
+	p.filterPay = searchCriteria.PayMin > 0 && searchCriteria.PayMax > 0
+	p.filterH1Year = searchCriteria.H1Year > 0
+	p.filterJobTitle = len(searchCriteria.JobTitle) > 0
+	p.state = strings.ToUpper(strings.TrimSpace(searchCriteria.State))
+	p.filterState = len(p.state) > 0
+	p.statuses = index.caseStatuses(searchCriteria)
+
+	if len(searchCriteria.WageLevels) > 0 {
+		p.levels = make(map[string]bool)
+		for _, level := range searchCriteria.WageLevels {
+			p.levels[domain.NormalizeWageLevel(level)] = true
+		}
+	}
+
+	if len(searchCriteria.VisaClasses) > 0 {
+		p.visas = make(map[string]bool)
+		for _, visaClass := range searchCriteria.VisaClasses {
+			p.visas[domain.NormalizeVisaClass(visaClass)] = true
+		}
+	}
+
+	//any of the codes of a system may match, at whatever level of the hierarchy each is given
+	for system, codes := range map[string][]string{domain.CodeSystemSoc: searchCriteria.SocCodes, domain.CodeSystemNaics: searchCriteria.NaicsCodes} {
+		for _, code := range codes {
+			if prefixes, ok := domain.CodePrefixes(system, code); ok {
+				if p.codes == nil {
+					p.codes = make(map[string][]string)
+				}
+				p.codes[system] = append(p.codes[system], prefixes...)
+			}
+		}
+	}
+
+	if len(searchCriteria.Zipcode) > 0 {
+		zipcode := "1" + fmt.Sprintf("%05s", strings.TrimSpace(searchCriteria.Zipcode))
+
+		radius := searchCriteria.Radius
+		if radius < 5 {
+			radius = 5
+		}
+
+		p.zipcodes = make(map[int]bool)
+		var cases []string
+
+		for i := 0; i < (radius/5)+1; i++ {
+			zipkey, err := strconv.Atoi(zipcode + fmt.Sprintf("%02d", i))
+			if err != nil {
+				index.log.Error(err.Error())
+			}
+
+			for _, zipcodeNearBy := range index.zipcodesNearBy[zipkey] {
+				if !p.zipcodes[zipcodeNearBy] {
+					p.zipcodes[zipcodeNearBy] = true
+					cases = append(cases, index.zipcodeCases[zipcodeNearBy]...)
+				}
+			}
+		}
+		p.candidates = append(p.candidates, cases)
+	}
+
+	if p.filterEmployer {
+		p.candidates = append(p.candidates, index.employerCases[searchCriteria.Employer])
+	}
+
+	//a state is only worth scanning when there is no zipcode to narrow by
+	if p.filterState && len(searchCriteria.Zipcode) == 0 {
+		p.candidates = append(p.candidates, index.stateCases[p.state])
+	}
+
+	return p
+}
+
+//caseStatuses a search accepts, nil when any status will do. A query on status
+//decides on its own, so status:DENIED and NOT status:CERTIFIED can match
+func (index caseIndex) caseStatuses(searchCriteria domain.SearchCriteria) map[string]bool {
+	if searchCriteria.AnyCaseStatus || (len(searchCriteria.CaseStatuses) == 0 && domain.QueryUses(searchCriteria.Query, "status")) {
+		return nil
+	}
+	wanted := searchCriteria.CaseStatuses
+	if len(wanted) == 0 {
+		wanted = index.certified
+	}
+	statuses := make(map[string]bool)
+	for _, status := range wanted {
+		statuses[strings.ToUpper(strings.TrimSpace(status))] = true
+	}
+	return statuses
+}
+
+//driver is the smallest candidate list, false when no index applies
+func (p plan) driver() ([]string, bool) {
+	if len(p.candidates) == 0 {
+		return nil, false
+	}
+	smallest := p.candidates[0]
+	for _, cases := range p.candidates[1:] {
+		if len(cases) < len(smallest) {
+			smallest = cases
+		}
+	}
+	return smallest, true
+}
+
+//matches checks every criterion, including the ones whose index did not drive the scan
+func (p plan) matches(lca domain.Lca) bool {
+	if p.zipcodes != nil {
+		zipcodeKey, _ := strconv.Atoi(lca.Employer_zip)
+		if !p.zipcodes[zipcodeKey] {
+			return false
+		}
+	}
+
+	if p.statuses != nil && !p.statuses[lca.Case_status] {
+		return false
+	}
+
+	if p.levels != nil && !p.levels[domain.NormalizeWageLevel(lca.Wage_level)] {
+		return false
+	}
+
+	if p.visas != nil && !p.visas[lca.VisaClass()] {
+		return false
+	}
+
+	for system, prefixes := range p.codes {
+		if !lca.HasCode(system, prefixes) {
+			return false
+		}
+	}
+
+	return (!p.filterEmployer || lca.EmployerNamed(p.criteria.Employer)) &&
+		(!p.filterPay || lca.PayBetween(p.criteria.PayMin, p.criteria.PayMax)) &&
+		(!p.filterH1Year || lca.YearIn(p.criteria.YearMode) == p.criteria.H1Year) &&
+		(!p.criteria.ExcludeH1Dependent || lca.H1b_dependent == "N") &&
+		(!p.criteria.ExcludeWillful || lca.Willful_voilator != "Y") &&
+		(!p.filterState || strings.EqualFold(lca.Employer_state, p.state) || strings.EqualFold(lca.Work_location_state, p.state)) &&
+		(!p.filterJobTitle || lca.HasJobTitle(p.criteria.JobTitle)) &&
+		(!p.criteria.FullTimeOnly || lca.Full_time == "Y") &&
+		(p.criteria.MinWorkers <= 0 || lca.Total_workers >= p.criteria.MinWorkers) &&
+		(p.criteria.MaxWorkers <= 0 || lca.Total_workers <= p.criteria.MaxWorkers) &&
+		(p.criteria.SubmitDates.IsZero() || p.criteria.SubmitDates.Contains(lca.Submit_date)) &&
+		(p.criteria.DecisionDates.IsZero() || p.criteria.DecisionDates.Contains(lca.Decision_date)) &&
+		(p.criteria.StartDates.IsZero() || p.criteria.StartDates.Contains(lca.Start_date)) &&
+		(p.criteria.EndDates.IsZero() || p.criteria.EndDates.Contains(lca.End_date)) &&
+		(p.criteria.Query == nil || p.criteria.Query.Match(lca))
+}
+
+//search returns the case numbers satisfying every criterion, each case once
+func (lcaRepo LcaRepo) search(searchCriteria domain.SearchCriteria) []string {
+	return lcaRepo.index().search(searchCriteria)
+}
+
+func (index caseIndex) search(searchCriteria domain.SearchCriteria) []string {
+	p := index.newPlan(searchCriteria)
+
+	var found []string
+	seen := make(map[string]bool)
+
+	check := func(casenum string) {
+		if seen[casenum] {
+			return
+		}
+		seen[casenum] = true
+		if lca, ok := index.cases[casenum]; ok && p.matches(lca) {
+			found = append(found, casenum)
+		}
+	}
+
+	if cases, ok := p.driver(); ok {
+		for _, casenum := range cases {
+			check(casenum)
+		}
+	} else if searchCriteria.Query != nil || p.codes != nil || p.visas != nil {
+		//a query, occupation, industry or visa class on its own has no index to start from, so scan every case
+		for casenum, lca := range index.cases {
+			if p.matches(lca) {
+				found = append(found, casenum)
+			}
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+func TestGetZipcodeAndEmployer(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Pay: 120000},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "160540", Pay: 120000},
+		domain.Lca{Case_number: "3", Employer_name: "ACME", Employer_zip: "194105", Pay: 120000},
+		domain.Lca{Case_number: "4", Employer_name: "INITECH", Employer_zip: "160523", Pay: 120000},
+		domain.Lca{Case_number: "5", Employer_name: "ACME", Employer_zip: "160523", Pay: 90000},
+	)
+	//case 1 filed again in a later year's file
+	lcaRepo.add(domain.Lca{Case_number: "1", Case_status: domain.StatusCertified, Employer_name: "ACME", Employer_zip: "160523", Pay: 120000})
+	lcaRepo.store.ZipcodesNearBy[16052300] = []int{160523}
+	lcaRepo.store.ZipcodesNearBy[16052301] = []int{160540}
+
+	it, _ := lcaRepo.Get(domain.SearchCriteria{Zipcode: "60523", Radius: 5, Employer: "ACME", PayMin: 100000, PayMax: 200000})
+	lcas := collect(it)
+	if len(lcas) != 2 {
+		t.Fatalf("got %d cases; want 2", len(lcas))
+	}
+	for _, lca := range lcas {
+		if lca.Case_number != "1" && lca.Case_number != "2" {
+			t.Errorf("got case %s; want only ACME cases within 5 miles paying 100K-200K", lca.Case_number)
+		}
+	}
+
+	it, _ = lcaRepo.Get(domain.SearchCriteria{Zipcode: "60523", Radius: 0, Employer: "ACME"})
+	lcas = collect(it)
+	if len(lcas) != 3 {
+		t.Errorf("got %d cases; want every ACME case within 5 miles", len(lcas))
+	}
+}
+
+func TestGetCaseStatuses(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Case_status: domain.StatusCertified, Employer_name: "ACME", Employer_zip: "160523"},
+		domain.Lca{Case_number: "2", Case_status: domain.StatusDenied, Employer_name: "ACME", Employer_zip: "160523"},
+		domain.Lca{Case_number: "3", Case_status: domain.StatusWithdrawn, Employer_name: "ACME", Employer_zip: "160523"},
+	)
+
+	for _, test := range []struct {
+		searchCriteria domain.SearchCriteria
+		want           int
+	}{
+		{domain.SearchCriteria{Employer: "ACME"}, 1},
+		{domain.SearchCriteria{Employer: "ACME", CaseStatuses: []string{"denied", domain.StatusWithdrawn}}, 2},
+		{domain.SearchCriteria{Employer: "ACME", AnyCaseStatus: true}, 3},
+		{domain.SearchCriteria{Employer: "ACME", Query: query(t, "status:DENIED")}, 1},
+		{domain.SearchCriteria{Employer: "ACME", Query: query(t, "NOT status:CERTIFIED")}, 2},
+		{domain.SearchCriteria{Query: query(t, "status:DENIED OR status:WITHDRAWN")}, 2},
+		{domain.SearchCriteria{Employer: "ACME", Query: query(t, "pay>0 OR employer:ACME")}, 1},
+	} {
+		it, _ := lcaRepo.Get(test.searchCriteria)
+		if it.Total() != test.want {
+			t.Errorf("%+v: got %d cases; want %d", test.searchCriteria, it.Total(), test.want)
+		}
+	}
+}
+
+func TestGetWageLevelsFullTimeAndWorkers(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Wage_level: "Level II", Full_time: "Y", Total_workers: 1},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "160523", Wage_level: "III", Full_time: "N", Total_workers: 1},
+		domain.Lca{Case_number: "3", Employer_name: "ACME", Employer_zip: "160523", Wage_level: "IV", Full_time: "Y", Total_workers: 10},
+	)
+
+	for _, test := range []struct {
+		searchCriteria domain.SearchCriteria
+		want           int
+	}{
+		{domain.SearchCriteria{Employer: "ACME", WageLevels: []string{"II", "III"}}, 2},
+		{domain.SearchCriteria{Employer: "ACME", FullTimeOnly: true}, 2},
+		{domain.SearchCriteria{Employer: "ACME", MinWorkers: 2}, 1},
+		{domain.SearchCriteria{Employer: "ACME", MaxWorkers: 1, FullTimeOnly: true}, 1},
+	} {
+		it, _ := lcaRepo.Get(test.searchCriteria)
+		if it.Total() != test.want {
+			t.Errorf("%+v: got %d cases; want %d", test.searchCriteria, it.Total(), test.want)
+		}
+	}
+}
+
+func TestGetDateRanges(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2019, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Submit_date: day(1, 1), Start_date: day(3, 1)},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "160523", Submit_date: day(1, 31), Start_date: day(6, 1)},
+		domain.Lca{Case_number: "3", Employer_name: "ACME", Employer_zip: "160523", Submit_date: day(2, 1)},
+	)
+
+	for _, test := range []struct {
+		searchCriteria domain.SearchCriteria
+		want           int
+	}{
+		{domain.SearchCriteria{Employer: "ACME", SubmitDates: domain.DateRange{From: day(1, 1), To: day(1, 31)}}, 2},
+		{domain.SearchCriteria{Employer: "ACME", SubmitDates: domain.DateRange{From: day(1, 31)}}, 2},
+		{domain.SearchCriteria{Employer: "ACME", StartDates: domain.DateRange{To: day(5, 31)}}, 1},
+		{domain.SearchCriteria{Employer: "ACME", EndDates: domain.DateRange{From: day(1, 1)}}, 0},
+	} {
+		it, _ := lcaRepo.Get(test.searchCriteria)
+		if it.Total() != test.want {
+			t.Errorf("%+v: got %d cases; want %d", test.searchCriteria, it.Total(), test.want)
+		}
+	}
+}
+
+func TestGetYearModes(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Year: 2019, Case_number: "1", Employer_name: "ACME", Employer_zip: "160523",
+			Submit_date: time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC), Start_date: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)},
+	)
+
+	for mode, want := range map[string]int{domain.YearModeFiscal: 2019, domain.YearModeFiling: 2018, domain.YearModeStart: 2019, "": 2019} {
+		it, _ := lcaRepo.Get(domain.SearchCriteria{Employer: "ACME", H1Year: want, YearMode: mode, Facets: []string{domain.FacetYear}})
+		if it.Total() != 1 || it.Facets()[domain.FacetYear][0].Value != strconv.Itoa(want) {
+			t.Errorf("%s: got %d cases, facets %v; want the case in %d", mode, it.Total(), it.Facets(), want)
+		}
+	}
+}
+
+func TestGetStateAndWillful(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Employer_state: "IL", Work_location_state: "IL"},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "160523", Employer_state: "IL", Work_location_state: "WA"},
+		domain.Lca{Case_number: "3", Employer_name: "INITECH", Employer_zip: "198101", Employer_state: "WA", Work_location_state: "WA", Willful_voilator: "Y"},
+	)
+
+	if got := lcaRepo.store.StateCases["IL"]; len(got) != 2 {
+		t.Errorf("got %v; want each IL case indexed once", got)
+	}
+
+	for _, test := range []struct {
+		searchCriteria domain.SearchCriteria
+		want           int
+	}{
+		{domain.SearchCriteria{State: "wa"}, 2},
+		{domain.SearchCriteria{State: "WA", ExcludeWillful: true}, 1},
+		{domain.SearchCriteria{State: "IL", Employer: "ACME"}, 2},
+		{domain.SearchCriteria{State: "TX"}, 0},
+	} {
+		it, _ := lcaRepo.Get(test.searchCriteria)
+		if it.Total() != test.want {
+			t.Errorf("%+v: got %d cases; want %d", test.searchCriteria, it.Total(), test.want)
+		}
+	}
+}
+
+func TestGetVisaClasses(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Pay: 100000},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "160523", Pay: 120000, Visa_class: domain.VisaE3},
+		domain.Lca{Case_number: "3", Employer_name: "ACME", Employer_zip: "160523", Pay: 140000, Visa_class: domain.VisaH1b1Chile},
+	)
+
+	it, _ := lcaRepo.Get(domain.SearchCriteria{Employer: "ACME", VisaClasses: []string{"e3", "H-1B1 Chile"}, Facets: []string{domain.FacetVisaClass}})
+	if it.Total() != 2 {
+		t.Errorf("got %d cases; want the E-3 and H-1B1 cases", it.Total())
+	}
+	want := []domain.FacetCount{{Value: domain.VisaE3, Count: 1}, {Value: domain.VisaH1b1Chile, Count: 1}}
+	if got := it.Facets()[domain.FacetVisaClass]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	it, _ = lcaRepo.Get(domain.SearchCriteria{Employer: "ACME", VisaClasses: []string{domain.VisaH1b}})
+	if lcas := collect(it); len(lcas) != 1 || lcas[0].Case_number != "1" {
+		t.Errorf("got %v; want a case without a visa class to count as H-1B", lcas)
+	}
+
+	stats, _ := lcaRepo.GetPayStats(domain.SearchCriteria{Employer: "ACME"}, domain.FacetVisaClass)
+	if len(stats) != 3 || stats[0].Group != domain.VisaE3 || stats[0].P50 != 120000 {
+		t.Errorf("got %v; want a group per visa class", stats)
+	}
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+type sortKey struct {
+	casenum string
+	n       int64
+	s       string
+	missing bool
+}
+
+//order sorts found case numbers by the criteria's sort key, ties and the
+//default order go by case number so pages stay stable between requests
+func (index caseIndex) order(found []string, searchCriteria domain.SearchCriteria) {
+	if len(searchCriteria.SortBy) == 0 {
+		sort.Strings(found)
+		return
+	}
+
+	var fromZipcode int
+	if searchCriteria.SortBy == domain.SortByDistance {
+		fromZipcode, _ = strconv.Atoi("1" + fmt.Sprintf("%05s", strings.TrimSpace(searchCriteria.Zipcode)))
+	}
+
+	keys := make([]sortKey, len(found))
+	for i, casenum := range found {
+		lca := index.cases[casenum]
+		key := sortKey{casenum: casenum}
+
+		//cases without a pay or date go last either way, like those without coordinates
+		switch searchCriteria.SortBy {
+		case domain.SortByPay:
+			key.n = int64(lca.Pay)
+			key.missing = lca.Pay == 0
+		case domain.SortBySubmitDate, domain.SortByDecisionDate, domain.SortByStartDate:
+			date := caseDate(lca, searchCriteria.SortBy)
+			key.n = date.Unix()
+			key.missing = date.IsZero()
+		case domain.SortByWorkers:
+			key.n = int64(lca.Total_workers)
+		case domain.SortByEmployer:
+			key.s = lca.Employer_name
+		case domain.SortByDistance:
+			//tenths of a mile, cases without coordinates go last either way
+			toZipcode, _ := strconv.Atoi(lca.Employer_zip)
+			miles, ok := zipcodeDistance(fromZipcode, toZipcode)
+			key.n = int64(miles * 10)
+			key.missing = !ok
+		}
+		keys[i] = key
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.missing != b.missing {
+			return b.missing
+		}
+		if a.n != b.n {
+			return a.n < b.n != searchCriteria.SortDescending
+		}
+		if a.s != b.s {
+			return a.s < b.s != searchCriteria.SortDescending
+		}
+		return a.casenum < b.casenum
+	})
+
+	for i, key := range keys {
+		found[i] = key.casenum
+	}
+}
+
+//zipcodeDistance in miles between two zipcode keys, false when either has no coordinates
+func zipcodeDistance(from int, to int) (float64, bool) {
+	fromGeoCoord, ok := zipcodeMap[from]
+	if !ok {
+		return 0, false
+	}
+	toGeoCoord, ok := zipcodeMap[to]
+	if !ok {
+		return 0, false
+	}
+	return getDistance(fromGeoCoord.lat, fromGeoCoord.long, toGeoCoord.lat, toGeoCoord.long), true
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+func TestGetSorted(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Pay: 120000},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "160523", Pay: 150000},
+		domain.Lca{Case_number: "3", Employer_name: "ACME", Employer_zip: "160523", Pay: 90000},
+		domain.Lca{Case_number: "4", Employer_name: "ACME", Employer_zip: "160523", Pay: 150000},
+	)
+
+	it, _ := lcaRepo.Get(domain.SearchCriteria{Employer: "ACME", SortBy: domain.SortByPay, SortDescending: true, PageSize: 3})
+	var got []string
+	for _, lca := range collect(it) {
+		got = append(got, lca.Case_number)
+	}
+	if strings.Join(got, ",") != "2,4,1" {
+		t.Errorf("got %v; want highest pay first, ties by case number", got)
+	}
+}
+
+func TestGetSortedByDistance(t *testing.T) {
+	defer func(coords map[int]*geoCoord) { zipcodeMap = coords }(zipcodeMap)
+	zipcodeMap = map[int]*geoCoord{
+		160523: {lat: 41.84, long: -87.95},
+		160540: {lat: 41.77, long: -88.14},
+		160601: {lat: 41.89, long: -87.62},
+	}
+
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160601"},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "199999"},
+		domain.Lca{Case_number: "3", Employer_name: "ACME", Employer_zip: "160540"},
+	)
+	lcaRepo.store.ZipcodesNearBy[16052300] = []int{160540, 160601, 199999}
+
+	for _, test := range []struct {
+		descending bool
+		want       string
+	}{{false, "3,1,2"}, {true, "1,3,2"}} {
+		it, _ := lcaRepo.Get(domain.SearchCriteria{Employer: "ACME", Zipcode: "60523", Radius: 5, SortBy: domain.SortByDistance, SortDescending: test.descending})
+		var got []string
+		for _, lca := range collect(it) {
+			got = append(got, lca.Case_number)
+		}
+		if strings.Join(got, ",") != test.want {
+			t.Errorf("descending %v: got %v; want %s, the case without coordinates last", test.descending, got, test.want)
+		}
+	}
+}
+
+func TestGetSortedMissingLast(t *testing.T) {
+	start := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Pay: 120000, Start_date: start.AddDate(0, 1, 0)},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "160523"},
+		domain.Lca{Case_number: "3", Employer_name: "ACME", Employer_zip: "160523", Pay: 90000, Start_date: start},
+	)
+
+	for _, test := range []struct {
+		sortBy     string
+		descending bool
+		want       string
+	}{
+		{domain.SortByStartDate, false, "3,1,2"},
+		{domain.SortByStartDate, true, "1,3,2"},
+		{domain.SortByPay, false, "3,1,2"},
+		{domain.SortByPay, true, "1,3,2"},
+	} {
+		it, _ := lcaRepo.Get(domain.SearchCriteria{Employer: "ACME", SortBy: test.sortBy, SortDescending: test.descending})
+		var got []string
+		for _, lca := range collect(it) {
+			got = append(got, lca.Case_number)
+		}
+		if strings.Join(got, ",") != test.want {
+			t.Errorf("%s descending %v: got %v; want %s, the case without one last", test.sortBy, test.descending, got, test.want)
+		}
+	}
+}
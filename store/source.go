@@ -0,0 +1,257 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+	log "github.com/kk3399/empnearme/log"
+)
+
+//kinds of data files a manifest can list
+const (
+	SourceLca         = "lca"
+	SourcePerm        = "perm"
+	SourceUscis       = "uscis"
+	SourceCorrections = "corrections"
+)
+
+//Source reads one data file and hands add each of its records, normalized: a domain.Lca,
+//a domain.Perm, a USCIS data hub row or a correction
+type Source interface {
+	Load(add func(record interface{})) error
+}
+
+//SourceEntry is one data file of a manifest. Year is the fiscal year the file covers,
+//corrections apply to every year and have none
+type SourceEntry struct {
+	Kind string
+	Year int
+	Path string
+}
+
+//Manifest lists the data files to load, in the order they are loaded
+type Manifest []SourceEntry
+
+const manifestFileName = "sources.json"
+
+//sourceKinds make the source reading a manifest entry
+var sourceKinds = map[string]func(entry SourceEntry, log log.Writer) Source{
+	SourceLca: func(entry SourceEntry, log log.Writer) Source {
+		return lcaSource{path: entry.Path, year: entry.Year, log: log}
+	},
+	SourcePerm: func(entry SourceEntry, log log.Writer) Source {
+		return permSource{path: entry.Path, year: entry.Year, log: log}
+	},
+	SourceUscis: func(entry SourceEntry, log log.Writer) Source {
+		return uscisSource{path: entry.Path, year: entry.Year}
+	},
+	SourceCorrections: func(entry SourceEntry, log log.Writer) Source {
+		return correctionsSource{path: entry.Path, log: log}
+	},
+}
+
+//ReadManifest reads a json manifest like [{"kind": "lca", "year": 2020, "path": "data/2020.csv"}],
+//the default manifest when there is no manifest file
+func ReadManifest(fileName string) (Manifest, error) {
+	if len(fileName) == 0 {
+		fileName = manifestFileName
+	}
+
+	f, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return DefaultManifest(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var manifest Manifest
+	if err = json.NewDecoder(f).Decode(&manifest); err != nil {
+		return nil, err
+	}
+	for i, entry := range manifest {
+		if _, ok := sourceKinds[entry.Kind]; !ok {
+			return nil, fmt.Errorf("%s: source %d has unknown kind %q", fileName, i+1, entry.Kind)
+		}
+		if len(entry.Path) == 0 {
+			return nil, fmt.Errorf("%s: source %d has no path", fileName, i+1)
+		}
+	}
+	return manifest, nil
+}
+
+//defaultFirstYears are the first fiscal years the default manifest looks for files of each kind,
+//the USCIS data hub goes back to 2009
+var defaultFirstYears = []struct {
+	kind string
+	year int
+}{{SourceLca, 2015}, {SourcePerm, 2015}, {SourceUscis, 2009}}
+
+//DefaultManifest lists a file of every kind for each year where the store always looked:
+//data/<year>.csv and data/perm/<year>.csv since 2015, data/uscis/<year>.csv since 2009
+//and data/corrections.csv
+func DefaultManifest() Manifest {
+	manifest := Manifest{{Kind: SourceCorrections, Path: path.Join("data", "corrections.csv")}}
+	for year := time.Now().Year(); year >= 2009; year-- {
+		for _, first := range defaultFirstYears {
+			if year < first.year {
+				continue
+			}
+			dir := "data"
+			if first.kind != SourceLca {
+				dir = path.Join("data", first.kind)
+			}
+			manifest = append(manifest, SourceEntry{Kind: first.kind, Year: year, Path: path.Join(dir, strconv.Itoa(year)+".csv")})
+		}
+	}
+	return manifest
+}
+
+//load every file of a kind, files that do not exist are skipped
+func (manifest Manifest) load(kind string, log log.Writer, add func(record interface{})) {
+	for _, entry := range manifest {
+		if entry.Kind != kind {
+			continue
+		}
+		log.Info("start: " + entry.Path)
+		err := sourceKinds[kind](entry, log).Load(add)
+		if err != nil && !os.IsNotExist(err) {
+			log.Error(entry.Path + ": " + err.Error())
+		}
+	}
+}
+
+//correction overrides one field of one case
+type correction struct {
+	casenum string
+	field   string
+	value   string
+}
+
+//correctionFields are the case fields a corrections file can override
+var correctionFields = map[string]func(lca *domain.Lca, value string) error{
+	"case_status":         func(lca *domain.Lca, value string) (err error) { lca.Case_status = strings.ToUpper(value); return },
+	"employer_name":       func(lca *domain.Lca, value string) (err error) { lca.Employer_name = strings.ToUpper(value); return },
+	"employer_city":       func(lca *domain.Lca, value string) (err error) { lca.Employer_city = value; return },
+	"employer_state":      func(lca *domain.Lca, value string) (err error) { lca.Employer_state = value; return },
+	"employer_fein":       func(lca *domain.Lca, value string) (err error) { lca.Employer_fein = value; return },
+	"job_title":           func(lca *domain.Lca, value string) (err error) { lca.Job_title = strings.ToUpper(value); return },
+	"soc_code":            func(lca *domain.Lca, value string) (err error) { lca.Soc_code = value; return },
+	"naics_code":          func(lca *domain.Lca, value string) (err error) { lca.Naics_code = value; return },
+	"wage_level":          func(lca *domain.Lca, value string) (err error) { lca.Wage_level = value; return },
+	"visa_class":          func(lca *domain.Lca, value string) (err error) { lca.Visa_class = value; return },
+	"work_location_city":  func(lca *domain.Lca, value string) (err error) { lca.Work_location_city = value; return },
+	"work_location_state": func(lca *domain.Lca, value string) (err error) { lca.Work_location_state = value; return },
+	"work_location_zip":   func(lca *domain.Lca, value string) (err error) { lca.Work_location_zip = value; return },
+	"employer_zip": func(lca *domain.Lca, value string) (err error) {
+		lca.Employer_zip = "1" + fmt.Sprintf("%05s", value)
+		return
+	},
+	"pay": func(lca *domain.Lca, value string) (err error) {
+		lca.Pay, err = strconv.Atoi(value)
+		return
+	},
+	"total_workers": func(lca *domain.Lca, value string) (err error) {
+		lca.Total_workers, err = strconv.Atoi(value)
+		return
+	},
+}
+
+//corrections by case number
+type corrections map[string][]correction
+
+//loadCorrections reads every corrections file of a manifest
+func loadCorrections(manifest Manifest, log log.Writer) corrections {
+	corrections := make(corrections)
+	manifest.load(SourceCorrections, log, func(record interface{}) {
+		c := record.(correction)
+		corrections[c.casenum] = append(corrections[c.casenum], c)
+	})
+	return corrections
+}
+
+//apply the corrections of a case, in the order they were listed
+func (corrections corrections) apply(lca *domain.Lca, log log.Writer) {
+	for _, c := range corrections[lca.Case_number] {
+		if err := correctionFields[c.field](lca, c.value); err != nil {
+			log.Error(fmt.Sprintf("correction of %s %s: %s", c.casenum, c.field, err.Error()))
+		}
+	}
+}
+
+//applyPerm corrects a PERM case through the fields it shares with its LCA view, wage_level
+//correcting the prevailing wage level and visa_class the class of admission. A PERM case is
+//for one worker, so total_workers is skipped
+func (corrections corrections) applyPerm(perm *domain.Perm, log log.Writer) {
+	if len(corrections[perm.Case_number]) == 0 {
+		return
+	}
+	lca := perm.AsLca()
+	lca.Employer_fein = perm.Employer_fein
+	corrections.apply(&lca, log)
+	if lca.Total_workers != 1 {
+		log.Error("cannot correct total_workers of PERM case " + perm.Case_number)
+	}
+
+	perm.Case_status = lca.Case_status
+	perm.Employer_name = lca.Employer_name
+	perm.Employer_city = lca.Employer_city
+	perm.Employer_state = lca.Employer_state
+	perm.Employer_zip = lca.Employer_zip
+	perm.Employer_fein = lca.Employer_fein
+	perm.Job_title = lca.Job_title
+	perm.Soc_code = lca.Soc_code
+	perm.Naics_code = lca.Naics_code
+	perm.Pw_level = lca.Wage_level
+	perm.Class_of_admission = lca.Visa_class
+	perm.Pay = lca.Pay
+	perm.Work_location_city = lca.Work_location_city
+	perm.Work_location_state = lca.Work_location_state
+	perm.Work_location_zip = lca.Work_location_zip
+}
+
+//correctionsSource reads a case_number,field,value csv, fields named like the correctionFields
+type correctionsSource struct {
+	path string
+	log  log.Writer
+}
+
+func (source correctionsSource) Load(add func(record interface{})) error {
+	rows, closer, err := openRows(source.path)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	for {
+		line, err := rows.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(line) != 3 {
+			return errors.New("corrections need a case number, a field and a value on each line")
+		}
+
+		c := correction{casenum: strings.TrimSpace(line[0]), field: strings.ToLower(strings.TrimSpace(line[1])), value: strings.TrimSpace(line[2])}
+		if c.casenum == "case_number" {
+			continue
+		}
+		if _, ok := correctionFields[c.field]; !ok {
+			source.log.Error("cannot correct " + c.field + " of " + c.casenum)
+			continue
+		}
+		add(c)
+	}
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	domain "github.com/kk3399/empnearme/domain"
+	log "github.com/kk3399/empnearme/log"
+)
+
+func TestReadManifestAndCorrections(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if manifest, err := ReadManifest(filepath.Join(dir, "sources.json")); err != nil || len(manifest) == 0 || manifest[0].Kind != SourceCorrections {
+		t.Errorf("got %v, %v; want the default manifest without a manifest file", manifest, err)
+	}
+
+	correctionsFile := filepath.Join(dir, "corrections.csv")
+	ioutil.WriteFile(correctionsFile, []byte("case_number,field,value\nI-1,employer_name,Acme Inc\nI-1,pay,120000\nI-2,salary,1\n"), 0644)
+	manifestFile := filepath.Join(dir, "sources.json")
+	ioutil.WriteFile(manifestFile, []byte(`[{"kind": "corrections", "path": "`+filepath.ToSlash(correctionsFile)+`"}, {"kind": "lca", "year": 2020, "path": "data/2020.csv"}]`), 0644)
+
+	manifest, err := ReadManifest(manifestFile)
+	if err != nil || len(manifest) != 2 || manifest[1] != (SourceEntry{Kind: SourceLca, Year: 2020, Path: "data/2020.csv"}) {
+		t.Fatalf("got %v, %v; want both sources", manifest, err)
+	}
+
+	corrections := make(corrections)
+	manifest.load(SourceCorrections, log.Writer{}, func(record interface{}) {
+		c := record.(correction)
+		corrections[c.casenum] = append(corrections[c.casenum], c)
+	})
+	lca := domain.Lca{Case_number: "I-1", Employer_name: "ACME", Pay: 100000}
+	corrections.apply(&lca, log.Writer{})
+	if lca.Employer_name != "ACME INC" || lca.Pay != 120000 || len(corrections["I-2"]) != 0 {
+		t.Errorf("got %+v, %v; want known fields corrected and unknown ones skipped", lca, corrections)
+	}
+
+	ioutil.WriteFile(manifestFile, []byte(`[{"kind": "h2b", "path": "data/h2b.csv"}]`), 0644)
+	if _, err := ReadManifest(manifestFile); err == nil {
+		t.Errorf("got no error; want unknown kinds rejected")
+	}
+}
+
+func TestDefaultManifest(t *testing.T) {
+	first := make(map[string]int)
+	for _, entry := range DefaultManifest() {
+		if entry.Kind != SourceCorrections {
+			first[entry.Kind] = entry.Year
+		}
+	}
+	if first[SourceLca] != 2015 || first[SourcePerm] != 2015 || first[SourceUscis] != 2009 {
+		t.Errorf("got first years %v; want lca and perm since 2015, uscis since 2009", first)
+	}
+}
+
+func TestCorrectSavedStore(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "I-1", Employer_name: "ACME", Employer_zip: "160523", Employer_state: "IL"},
+		domain.Lca{Case_number: "I-2", Employer_name: "ACME", Employer_zip: "160523", Employer_state: "IL"},
+	)
+	lcaRepo.correct(corrections{
+		"I-1": {{casenum: "I-1", field: "employer_name", value: "Acme Inc"}, {casenum: "I-1", field: "employer_state", value: "WI"}},
+		"I-9": {{casenum: "I-9", field: "employer_name", value: "Nobody"}},
+	})
+
+	if lca := lcaRepo.store.Cases["I-1"]; lca.Employer_name != "ACME INC" || lca.Employer_state != "WI" {
+		t.Errorf("got %+v; want the saved case corrected", lca)
+	}
+	it, _ := lcaRepo.Get(domain.SearchCriteria{Employer: "ACME INC"})
+	if lcas := collect(it); len(lcas) != 1 || lcas[0].Case_number != "I-1" {
+		t.Errorf("got %v; want the case found under its corrected employer", lcas)
+	}
+	if casenums := lcaRepo.store.EmployerCases["ACME"]; len(casenums) != 1 || casenums[0] != "I-2" {
+		t.Errorf("got %v; want the case gone from its old employer", casenums)
+	}
+	if casenums := lcaRepo.store.StateCases["WI"]; len(casenums) != 1 || len(lcaRepo.store.StateCases["IL"]) != 1 {
+		t.Errorf("got %v; want the case moved to its corrected state", lcaRepo.store.StateCases)
+	}
+	if _, ok := lcaRepo.store.Cases["I-9"]; ok {
+		t.Errorf("got a case from a correction alone; want corrections of unknown cases skipped")
+	}
+}
+
+func TestApplyPermCorrections(t *testing.T) {
+	perm := domain.Perm{Case_number: "A-1", Employer_name: "ACME", Pw_level: "I", Pay: 100000}
+	corrections{"A-1": {
+		{casenum: "A-1", field: "employer_name", value: "Acme Inc"},
+		{casenum: "A-1", field: "wage_level", value: "II"},
+		{casenum: "A-1", field: "pay", value: "120000"},
+		{casenum: "A-1", field: "total_workers", value: "3"},
+	}}.applyPerm(&perm, log.Writer{})
+	if perm.Employer_name != "ACME INC" || perm.Pw_level != "II" || perm.Pay != 120000 {
+		t.Errorf("got %+v; want the PERM case corrected", perm)
+	}
+}
+
+func TestInitPermKeepsLcaCorrections(t *testing.T) {
+	dir, err := ioutil.TempDir("", "perm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	permFile := filepath.Join(dir, "PERM_Disclosure_Data_FY2020.csv")
+	ioutil.WriteFile(permFile, []byte("CASE_NUMBER,CASE_STATUS,EMPLOYER_NAME,EMPLOYER_POSTAL_CODE\nA-1,Certified,Acme,60523\n"), 0644)
+
+	//the manifest lists no corrections files, those Init read come with the LCA repo
+	lcaRepo := newTestRepo()
+	lcaRepo.corrections = corrections{"A-1": {{casenum: "A-1", field: "employer_name", value: "Acme Inc"}}}
+	permRepo := InitPerm(log.Writer{}, lcaRepo, Manifest{{Kind: SourcePerm, Year: 2020, Path: permFile}})
+	if perm := permRepo.store.Cases["A-1"]; perm.Employer_name != "ACME INC" {
+		t.Errorf("got %+v; want the PERM case corrected by the LCA repo's corrections", perm)
+	}
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"math"
+	"sort"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+const constStatsGroupCap = 100
+
+//GetPayStats of cases matching the criteria, grouped by a facet or as one group when groupBy is empty.
+//Cases without a yearly pay are left out
+func (lcaRepo LcaRepo) GetPayStats(searchCriteria domain.SearchCriteria, groupBy string) ([]domain.PayStats, error) {
+	return lcaRepo.index().payStatsBy(searchCriteria, groupBy), nil
+}
+
+func (index caseIndex) payStatsBy(searchCriteria domain.SearchCriteria, groupBy string) []domain.PayStats {
+	groups := make(map[string][]int)
+	for _, casenum := range index.search(searchCriteria) {
+		lca := index.cases[casenum]
+		if lca.Pay <= 0 {
+			continue
+		}
+		group := ""
+		if len(groupBy) > 0 {
+			group = facetValue(lca, groupBy, searchCriteria.YearMode)
+		}
+		groups[group] = append(groups[group], lca.Pay)
+	}
+
+	stats := make([]domain.PayStats, 0, len(groups))
+	for group, pays := range groups {
+		stats = append(stats, payStats(group, pays))
+	}
+
+	//keep the largest groups, then list them in group order
+	if len(stats) > constStatsGroupCap {
+		sort.Slice(stats, func(i, j int) bool {
+			return stats[i].Count > stats[j].Count
+		})
+		stats = stats[:constStatsGroupCap]
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Group < stats[j].Group
+	})
+
+	return stats
+}
+
+//payStats sorts pays in place
+func payStats(group string, pays []int) domain.PayStats {
+	sort.Ints(pays)
+
+	total := 0
+	for _, pay := range pays {
+		total += pay
+	}
+
+	stats := domain.PayStats{Group: group, Count: len(pays)}
+	if len(pays) > 0 {
+		stats.Mean = int(math.Round(float64(total) / float64(len(pays))))
+		stats.P10 = percentile(pays, 0.10)
+		stats.P25 = percentile(pays, 0.25)
+		stats.P50 = percentile(pays, 0.50)
+		stats.P75 = percentile(pays, 0.75)
+		stats.P90 = percentile(pays, 0.90)
+	}
+	return stats
+}
+
+//percentile of sorted values, interpolating between the closest ranks
+func percentile(sorted []int, p float64) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p * float64(len(sorted)-1)
+	lo := int(rank)
+	if lo+1 >= len(sorted) {
+		return sorted[lo]
+	}
+	return int(math.Round(float64(sorted[lo]) + (rank-float64(lo))*float64(sorted[lo+1]-sorted[lo])))
+}
@@ -0,0 +1,28 @@
+package store
+
+import (
+	"strconv"
+	"testing"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+func TestGetPayStats(t *testing.T) {
+	var lcas []domain.Lca
+	for i, pay := range []int{100000, 110000, 120000, 130000, 140000, 0} {
+		lcas = append(lcas, domain.Lca{Case_number: strconv.Itoa(i), Employer_name: "ACME", Employer_zip: "160523", Pay: pay, Wage_level: "II"})
+	}
+	lcas[0].Wage_level = "I"
+	lcaRepo := newTestRepo(lcas...)
+
+	stats, _ := lcaRepo.GetPayStats(domain.SearchCriteria{Employer: "ACME"}, "")
+	want := domain.PayStats{Count: 5, Mean: 120000, P10: 104000, P25: 110000, P50: 120000, P75: 130000, P90: 136000}
+	if len(stats) != 1 || stats[0] != want {
+		t.Errorf("got %v; want %v", stats, want)
+	}
+
+	stats, _ = lcaRepo.GetPayStats(domain.SearchCriteria{Employer: "ACME"}, domain.FacetWageLevel)
+	if len(stats) != 2 || stats[0].Group != "I" || stats[1].Count != 4 {
+		t.Errorf("got %v; want a group per wage level", stats)
+	}
+}
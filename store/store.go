@@ -5,11 +5,13 @@ import (
 	"encoding/gob"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
-	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	domain "github.com/kk3399/empnearme/domain"
@@ -18,8 +20,12 @@ import (
 
 //LcaRepo - data infrastructure
 type LcaRepo struct {
-	store store
-	log   log.Writer
+	store       store
+	log         log.Writer
+	employerIDs map[string]string
+	titleCounts map[string]int
+	codes       map[string]codeTable
+	corrections corrections
 }
 
 type store struct {
@@ -27,6 +33,7 @@ type store struct {
 	EmployerCases  map[string][]string
 	ZipcodeCases   map[int][]string
 	ZipcodesNearBy map[int][]int
+	StateCases     map[string][]string
 }
 
 //geoCoord type
@@ -43,17 +50,26 @@ type caseDistance struct {
 
 const (
 	constLcaResponseCap   = 5000
+	constDefaultPageSize  = 500
 	constMaxRadiusInMiles = 51
+	constTitleListCap     = 20
 )
 
+//zipcodeMap has the coordinates of every zipcode, read once by Init and only read after
 var zipcodeMap map[int]*geoCoord
+var zipcodeMapOnce sync.Once
 
 const zipcodemapFileName = "zipcodemap.csv"
 const datastoreFilename = "data.gob"
 
-//Init database
-func Init(log log.Writer) LcaRepo {
+//Init database, loading the LCA files of the manifest when there is no datastore file.
+//The corrections files of the manifest apply either way, and are kept for InitPerm
+func Init(log log.Writer, manifest Manifest) LcaRepo {
 	lcaRepo := LcaRepo{log: log}
+	if err := loadZipCodesIfNeeded(); err != nil {
+		log.Error("zipcode coordinates: " + err.Error())
+	}
+	lcaRepo.corrections = loadCorrections(manifest, log)
 	if _, err := os.Stat(datastoreFilename); os.IsNotExist(err) {
 		log.Info("initializing databases: ")
 		lcaRepo.store = store{
@@ -61,9 +77,9 @@ func Init(log log.Writer) LcaRepo {
 			EmployerCases:  make(map[string][]string),
 			ZipcodeCases:   make(map[int][]string),
 			ZipcodesNearBy: make(map[int][]int),
+			StateCases:     make(map[string][]string),
 		}
-		lcaRepo.loadStore()
-		cleanTempMaps()
+		lcaRepo.loadStore(manifest, lcaRepo.corrections)
 		//lcaRepo.save()
 		//runtime.GC()
 		log.Info("DONE initializing databases: ")
@@ -76,13 +92,17 @@ func Init(log log.Writer) LcaRepo {
 		} else {
 			lcaRepo.store = *dataStore
 		}
+		if len(lcaRepo.store.StateCases) == 0 {
+			lcaRepo.store.StateCases = stateCases(lcaRepo.store.Cases)
+		}
+		lcaRepo.correct(lcaRepo.corrections)
 	}
 
-	return lcaRepo
-}
+	lcaRepo.employerIDs = employerIDs(lcaRepo.store.EmployerCases)
+	lcaRepo.titleCounts = titleCounts(lcaRepo.store.Cases)
+	lcaRepo.codes = lcaRepo.loadCodes()
 
-func cleanTempMaps() {
-	zipcodeMap = make(map[int]*geoCoord)
+	return lcaRepo
 }
 
 //Close database connection
@@ -113,13 +133,13 @@ func readGob(filePath string, object interface{}) error {
 	return err
 }
 
-//Load loads all lca from flat files
-func (lcaRepo LcaRepo) loadStore() {
-	loadZipCodesIfNeeded()
-
-	for year := time.Now().Year(); year >= 2015; year-- {
-		lcaRepo.loadYear(year)
-	}
+//Load loads all lca from flat files, corrected by the corrections files
+func (lcaRepo LcaRepo) loadStore(manifest Manifest, corrections corrections) {
+	manifest.load(SourceLca, lcaRepo.log, func(record interface{}) {
+		lca := record.(domain.Lca)
+		corrections.apply(&lca, lcaRepo.log)
+		lcaRepo.add(lca)
+	})
 
 	for zipcodeFrom := range zipcodeMap {
 		geoCoordFrom, _ := getGeoCoordFromZip(zipcodeFrom)
@@ -157,89 +177,81 @@ func (lcaRepo LcaRepo) GetEmployerNames(start string) map[string]int {
 	return r
 }
 
-//Get lcas
-func (lcaRepo LcaRepo) Get(searchCriteria domain.SearchCriteria) ([]domain.Lca, error) {
-
-	var filterEmployer, filterPay, filterH1Year, excludeH1Dependent, filterJobTitle bool
-	var lcas []domain.Lca
-
-	if len(searchCriteria.Employer) > 0 {
-		filterEmployer = true
+//titleCounts is how many cases were filed under each job title
+func titleCounts(cases map[string]domain.Lca) map[string]int {
+	counts := make(map[string]int)
+	for _, lca := range cases {
+		counts[lca.Job_title]++
 	}
+	return counts
+}
 
-	if searchCriteria.PayMin > 0 && searchCriteria.PayMax > 0 {
-		filterPay = true
-	}
+//GetJobTitles to return the most filed job titles containing has for autocomplete,
+//optionally scoped to an employer and/or an employer zipcode. Unscoped lookups
+//filter the title counts kept since Init instead of walking every case
+func (lcaRepo LcaRepo) GetJobTitles(has string, employer string, zipcode string) []domain.TitleCount {
+	counts := make(map[string]int)
 
-	if searchCriteria.H1Year > 0 {
-		filterH1Year = true
-	}
-
-	if searchCriteria.ExcludeH1Dependent {
-		excludeH1Dependent = true
+	count := func(lca domain.Lca) {
+		if len(zipcode) > 0 && lca.Employer_zip != zipcode {
+			return
+		}
+		if lca.HasJobTitle(has) {
+			counts[lca.Job_title]++
+		}
 	}
 
-	if len(searchCriteria.JobTitle) > 0 {
-		filterJobTitle = true
+	if len(zipcode) > 0 {
+		zipcode = "1" + fmt.Sprintf("%05s", strings.TrimSpace(zipcode))
 	}
 
-	if len(searchCriteria.Zipcode) > 0 {
-
-		searchCriteria.Zipcode = "1" + fmt.Sprintf("%05s", strings.TrimSpace(searchCriteria.Zipcode))
-
-		if searchCriteria.Radius < 5 {
-			searchCriteria.Radius = 5
+	if len(employer) > 0 {
+		for _, casenum := range lcaRepo.store.EmployerCases[employer] {
+			count(lcaRepo.store.Cases[casenum])
 		}
-
-		var cases []string
-
-		for i := 0; i < (searchCriteria.Radius/5)+1; i++ {
-			zipkey, err := strconv.Atoi(searchCriteria.Zipcode + fmt.Sprintf("%02d", i))
-			if err != nil {
-				lcaRepo.log.Error(err.Error())
-			}
-
-			for _, casenum := range lcaRepo.store.ZipcodesNearBy[zipkey] {
-				cases = append(cases, lcaRepo.store.ZipcodeCases[casenum]...)
-			}
-
+	} else if len(zipcode) > 0 {
+		zipcodeKey, _ := strconv.Atoi(zipcode)
+		for _, casenum := range lcaRepo.store.ZipcodeCases[zipcodeKey] {
+			count(lcaRepo.store.Cases[casenum])
 		}
-
-		for _, casenum := range cases {
-			if len(lcas) > constLcaResponseCap {
-				break
-			}
-			lca := lcaRepo.store.Cases[casenum]
-			if (!filterEmployer || lca.EmployerNamed(searchCriteria.Employer)) &&
-				(!filterPay || lca.PayBetween(searchCriteria.PayMin, searchCriteria.PayMax)) &&
-				(!filterH1Year || lca.Start_date.Year() == searchCriteria.H1Year) &&
-				(!excludeH1Dependent || lca.H1b_dependent == "N") &&
-				(!filterJobTitle || lca.HasJobTitle(searchCriteria.JobTitle)) {
-				lcas = append(lcas, lca)
+	} else {
+		for title, n := range lcaRepo.titleCounts {
+			if strings.Contains(title, has) {
+				counts[title] = n
 			}
 		}
 	}
 
-	if filterEmployer {
-		for _, casenum := range lcaRepo.store.EmployerCases[searchCriteria.Employer] {
-			if len(lcas) > constLcaResponseCap {
-				break
-			}
-			lca := lcaRepo.store.Cases[casenum]
-			if (!filterPay || lca.PayBetween(searchCriteria.PayMin, searchCriteria.PayMax)) &&
-				(!filterH1Year || lca.Start_date.Year() == searchCriteria.H1Year) &&
-				(!excludeH1Dependent || lca.H1b_dependent == "N") &&
-				(!filterJobTitle || lca.HasJobTitle(searchCriteria.JobTitle)) {
-				lcas = append(lcas, lca)
-			}
+	titles := make([]domain.TitleCount, 0, len(counts))
+	for title, n := range counts {
+		titles = append(titles, domain.TitleCount{Title: title, Count: n})
+	}
+	sort.Slice(titles, func(i, j int) bool {
+		if titles[i].Count != titles[j].Count {
+			return titles[i].Count > titles[j].Count
 		}
+		return titles[i].Title < titles[j].Title
+	})
+
+	if len(titles) > constTitleListCap {
+		return titles[:constTitleListCap]
 	}
+	return titles
+}
+
+//Get lcas, one page at a time in the criteria's sort order
+func (lcaRepo LcaRepo) Get(searchCriteria domain.SearchCriteria) (domain.LcaIterator, error) {
+	index := lcaRepo.index()
+	found := index.search(searchCriteria)
+	index.order(found, searchCriteria)
 
-	if len(lcas) > constLcaResponseCap {
-		return lcas[:constLcaResponseCap], nil
+	page, size, nextCursor, err := paginate(found, searchCriteria.PageSize, searchCriteria.Cursor)
+	if err != nil {
+		return nil, err
 	}
 
-	return lcas, nil
+	return &lcaIterator{cases: lcaRepo.store.Cases, page: page, total: len(found), pageSize: size, nextCursor: nextCursor,
+		facets: index.facets(found, searchCriteria)}, nil
 }
 
 func (lcaRepo LcaRepo) add(lca domain.Lca) error {
@@ -263,35 +275,165 @@ func (lcaRepo LcaRepo) add(lca domain.Lca) error {
 		val.inUse = true
 	}
 
+	for _, state := range caseStates(lca) {
+		lcaRepo.store.StateCases[state] = append(lcaRepo.store.StateCases[state], lca.Case_number)
+	}
+
 	return nil
 }
 
-func (lcaRepo LcaRepo) loadYear(year int) error {
+//remove takes a case out of the store and its indexes
+func (lcaRepo LcaRepo) remove(lca domain.Lca) {
+	delete(lcaRepo.store.Cases, lca.Case_number)
+
+	if casenums := without(lcaRepo.store.EmployerCases[lca.Employer_name], lca.Case_number); len(casenums) > 0 {
+		lcaRepo.store.EmployerCases[lca.Employer_name] = casenums
+	} else {
+		delete(lcaRepo.store.EmployerCases, lca.Employer_name)
+	}
+
+	zipcodeKey, _ := strconv.Atoi(lca.Employer_zip)
+	if casenums := without(lcaRepo.store.ZipcodeCases[zipcodeKey], lca.Case_number); len(casenums) > 0 {
+		lcaRepo.store.ZipcodeCases[zipcodeKey] = casenums
+	} else {
+		delete(lcaRepo.store.ZipcodeCases, zipcodeKey)
+	}
+
+	for _, state := range caseStates(lca) {
+		if casenums := without(lcaRepo.store.StateCases[state], lca.Case_number); len(casenums) > 0 {
+			lcaRepo.store.StateCases[state] = casenums
+		} else {
+			delete(lcaRepo.store.StateCases, state)
+		}
+	}
+}
+
+//without is a list of case numbers less one of them
+func without(casenums []string, casenum string) []string {
+	var rest []string
+	for _, c := range casenums {
+		if c != casenum {
+			rest = append(rest, c)
+		}
+	}
+	return rest
+}
+
+//correct applies corrections to the cases of a saved datastore, re-indexing the corrected cases
+//so a corrected employer, zipcode or state finds them
+func (lcaRepo LcaRepo) correct(corrections corrections) {
+	for casenum := range corrections {
+		lca, ok := lcaRepo.store.Cases[casenum]
+		if !ok {
+			continue
+		}
+		lcaRepo.remove(lca)
+		corrections.apply(&lca, lcaRepo.log)
+		lcaRepo.add(lca)
+	}
+}
+
+//caseStates are the employer and worksite states of a case, once each
+func caseStates(lca domain.Lca) []string {
+	var states []string
+	for _, state := range []string{lca.Employer_state, lca.Work_location_state} {
+		state = strings.ToUpper(strings.TrimSpace(state))
+		if len(state) > 0 && (len(states) == 0 || states[0] != state) {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+//stateCases indexes cases of a datastore saved before states were indexed
+func stateCases(cases map[string]domain.Lca) map[string][]string {
+	index := make(map[string][]string)
+	for casenum, lca := range cases {
+		for _, state := range caseStates(lca) {
+			index[state] = append(index[state], casenum)
+		}
+	}
+	return index
+}
+
+//lcaSource reads the LCA files of one fiscal year
+type lcaSource struct {
+	path string
+	year int
+	log  log.Writer
+}
 
-	lcaRepo.log.Info(fmt.Sprintf("start: %d", year))
-	fileName := path.Join("data", strconv.Itoa(year)+".csv")
+//lcaColumns are the DOL LCA disclosure headers each field is read from, files of different years name some differently
+var lcaColumns = map[string][]string{
+	"case_number":         {"CASE_NUMBER", "LCA_CASE_NUMBER"},
+	"case_status":         {"CASE_STATUS", "STATUS"},
+	"submit_date":         {"RECEIVED_DATE", "CASE_SUBMITTED", "LCA_CASE_SUBMIT"},
+	"decision_date":       {"DECISION_DATE"},
+	"start_date":          {"BEGIN_DATE", "EMPLOYMENT_START_DATE", "LCA_CASE_EMPLOYMENT_START_DATE"},
+	"end_date":            {"END_DATE", "EMPLOYMENT_END_DATE", "LCA_CASE_EMPLOYMENT_END_DATE"},
+	"employer_name":       {"EMPLOYER_NAME", "LCA_CASE_EMPLOYER_NAME"},
+	"employer_address":    {"EMPLOYER_ADDRESS1", "EMPLOYER_ADDRESS"},
+	"employer_city":       {"EMPLOYER_CITY"},
+	"employer_state":      {"EMPLOYER_STATE"},
+	"employer_zip":        {"EMPLOYER_POSTAL_CODE"},
+	"employer_fein":       {"EMPLOYER_FEIN"},
+	"job_title":           {"JOB_TITLE"},
+	"soc_code":            {"SOC_CODE"},
+	"soc_name":            {"SOC_TITLE", "SOC_NAME"},
+	"naics_code":          {"NAICS_CODE", "NAIC_CODE"},
+	"total_workers":       {"TOTAL_WORKER_POSITIONS", "TOTAL_WORKERS"},
+	"full_time":           {"FULL_TIME_POSITION"},
+	"wage_rate":           {"WAGE_RATE_OF_PAY_FROM", "WAGE_RATE_OF_PAY"},
+	"wage_unit":           {"WAGE_UNIT_OF_PAY"},
+	"wage_level":          {"PW_WAGE_LEVEL", "PW_LEVEL"},
+	"h1b_dependent":       {"H_1B_DEPENDENT", "H1B_DEPENDENT"},
+	"willful_violator":    {"WILLFUL_VIOLATOR"},
+	"work_location_city":  {"WORKSITE_CITY", "WORKSITE_CITY_1"},
+	"work_location_state": {"WORKSITE_STATE", "WORKSITE_STATE_1"},
+	"work_location_zip":   {"WORKSITE_POSTAL_CODE", "WORKSITE_POSTAL_CODE_1"},
+	"visa_class":          {"VISA_CLASS"},
+}
+
+func (source lcaSource) Load(add func(record interface{})) error {
 	/*
 		1-year	2-case_number	3-case_status	4-submit_date	5-decision_date	6-start_date	7-end_date	8-employer_name	9-employer_address
-		10-employer_city	11-employer_state	12-employer_zip	13-job_title	14-naics_code	15-total_workers	16-full_time	17-wage_rate
-		18-wage_unit	19-wage_level	20-h1b_dependent	21-willful_voilator	22-work_location_city	23-work_location_state	24-work_location_zip
+		10-employer_city	11-employer_state	12-employer_zip	13-job_title	14-soc_code	15-soc_name	16-naics_code	17-total_workers
+		18-full_time	19-wage_rate	20-wage_unit	21-wage_level	22-h1b_dependent	23-willful_voilator	24-work_location_city
+		25-work_location_state	26-work_location_zip
+		27-visa_class, left out by files of H-1B cases only	28-employer_fein, left out by files before FEINs were published
 	*/
+	const positionalColumns = 26
 
-	f, err := os.Open(fileName)
+	rows, closer, err := openRows(source.path)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	defer closer.Close()
 
-	// Read File into a Variable
-	lines, err := csv.NewReader(f).ReadAll()
+	first, err := rows.Read()
+	if err == io.EOF {
+		return nil
+	}
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	//raw DOL disclosure files name their columns, prepared files list them in the order above
+	if header := newColumns(first); header.has(lcaColumns["case_number"]) {
+		return source.readDol(header, rows, add)
 	}
+
 	dateLayout := "1/2/2006"
 	dateAlternateLayout := "2/1/2006"
 
 	// Loop through lines & turn into object
-	for _, line := range lines {
+	var readErr error
+	for line := first; readErr == nil; line, readErr = rows.Read() {
+		//xlsx rows leave out trailing empty cells and csv rows may be cut short
+		if len(line) < positionalColumns {
+			source.log.Error(fmt.Sprintf("%s: skipping a row of %d columns, want %d: %s", source.path, len(line), positionalColumns, strings.Join(line, ",")))
+			continue
+		}
 		i := 0
 
 		if line[i] != "year" {
@@ -309,7 +451,7 @@ func (lcaRepo LcaRepo) loadYear(year int) error {
 				if err != nil {
 					lca.Submit_date, err = time.Parse(dateAlternateLayout, dt)
 					if err != nil {
-						lcaRepo.log.Write(err)
+						source.log.Write(err)
 					}
 				}
 			}
@@ -321,7 +463,7 @@ func (lcaRepo LcaRepo) loadYear(year int) error {
 				if err != nil {
 					lca.Decision_date, err = time.Parse(dateAlternateLayout, dt)
 					if err != nil {
-						lcaRepo.log.Write(err)
+						source.log.Write(err)
 					}
 				}
 			}
@@ -333,7 +475,7 @@ func (lcaRepo LcaRepo) loadYear(year int) error {
 				if err != nil {
 					lca.Start_date, err = time.Parse(dateAlternateLayout, dt)
 					if err != nil {
-						lcaRepo.log.Write(err)
+						source.log.Write(err)
 					}
 				}
 			}
@@ -345,7 +487,7 @@ func (lcaRepo LcaRepo) loadYear(year int) error {
 				if err != nil {
 					lca.End_date, err = time.Parse(dateAlternateLayout, dt)
 					if err != nil {
-						lcaRepo.log.Write(err)
+						source.log.Write(err)
 					}
 				}
 			}
@@ -390,18 +532,102 @@ func (lcaRepo LcaRepo) loadYear(year int) error {
 			lca.Work_location_state = strings.TrimSpace(line[i])
 			i = i + 1
 			lca.Work_location_zip = strings.TrimSpace(line[i])
+			i = i + 1
+			lca.Visa_class = domain.VisaH1b
+			if i < len(line) {
+				lca.Visa_class = strings.TrimSpace(line[i])
+				if visaClass := domain.NormalizeVisaClass(lca.Visa_class); len(visaClass) > 0 {
+					lca.Visa_class = visaClass
+				}
+			}
+			i = i + 1
+			if i < len(line) {
+				lca.Employer_fein = strings.TrimSpace(line[i])
+			}
 
 			if err == nil {
-				err = lcaRepo.add(lca)
+				add(lca)
 			}
 		}
 
 	}
-	lcaRepo.log.Info(fmt.Sprintf("end: %d", year))
+	if readErr != io.EOF {
+		return readErr
+	}
 
 	return nil
 }
 
+//readDol reads a raw DOL disclosure file by its header. Unlike prepared files, wages are
+//given in any unit of pay and are made yearly like PERM wages
+func (source lcaSource) readDol(header columns, rows rowReader, add func(record interface{})) error {
+	for {
+		line, err := rows.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		field := func(name string) string {
+			return header.field(line, lcaColumns[name])
+		}
+
+		lca := domain.Lca{Year: source.year}
+		lca.Case_number = field("case_number")
+		if len(lca.Case_number) == 0 {
+			continue
+		}
+		lca.Case_status = strings.ToUpper(field("case_status"))
+		lca.Submit_date = parseDolDate(field("submit_date"), source.log)
+		lca.Decision_date = parseDolDate(field("decision_date"), source.log)
+		lca.Start_date = parseDolDate(field("start_date"), source.log)
+		lca.End_date = parseDolDate(field("end_date"), source.log)
+		lca.Employer_name = strings.ToUpper(field("employer_name"))
+		lca.Employer_address = field("employer_address")
+		lca.Employer_city = field("employer_city")
+		lca.Employer_state = field("employer_state")
+		lca.Employer_zip = "1" + fmt.Sprintf("%05s", firstN(field("employer_zip"), 5))
+		lca.Employer_fein = field("employer_fein")
+		lca.Job_title = strings.ToUpper(field("job_title"))
+		lca.Soc_code = field("soc_code")
+		lca.Soc_name = field("soc_name")
+		lca.Naics_code = field("naics_code")
+		lca.Total_workers, _ = strconv.Atoi(field("total_workers"))
+		lca.Full_time = yesNo(field("full_time"))
+		lca.Wage_rate = field("wage_rate")
+		lca.Wage_unit = field("wage_unit")
+		lca.Wage_level = field("wage_level")
+		lca.H1b_dependent = yesNo(field("h1b_dependent"))
+		lca.Willful_voilator = yesNo(field("willful_violator"))
+		lca.Work_location_city = field("work_location_city")
+		lca.Work_location_state = field("work_location_state")
+		lca.Work_location_zip = firstN(field("work_location_zip"), 5)
+		lca.Visa_class = domain.VisaH1b
+		if visaClass := field("visa_class"); len(visaClass) > 0 {
+			lca.Visa_class = visaClass
+			if normalized := domain.NormalizeVisaClass(visaClass); len(normalized) > 0 {
+				lca.Visa_class = normalized
+			}
+		}
+
+		lca.Pay = yearlyPay(lca.Wage_rate, lca.Wage_unit)
+
+		add(lca)
+	}
+}
+
+//yesNo turns the Yes and No of newer DOL files into the Y and N of older ones
+func yesNo(s string) string {
+	switch strings.ToUpper(s) {
+	case "YES", "Y":
+		return "Y"
+	case "NO", "N":
+		return "N"
+	}
+	return s
+}
+
 func getPay(wage string, unit string) (int, error) {
 	p := 0
 	if wage != "" {
@@ -414,59 +640,80 @@ func getPay(wage string, unit string) (int, error) {
 	return p, nil
 }
 
-func loadZipCodesIfNeeded() {
-	if zipcodeMap == nil {
-		err := loadZipcodeMap()
-		if err != nil {
-			panic("error loading zipcode to lat,long csv file to a map")
+//loadZipCodesIfNeeded reads the zipcode coordinates the first time, later calls return at once
+//and never change the map. Without the file no zipcode has coordinates, the error is given only
+//to the first caller
+func loadZipCodesIfNeeded() error {
+	var err error
+	zipcodeMapOnce.Do(func() {
+		var coords map[int]*geoCoord
+		if coords, err = loadZipcodeMap(); err == nil {
+			zipcodeMap = coords
+		} else if zipcodeMap == nil {
+			zipcodeMap = make(map[int]*geoCoord)
 		}
-	}
+	})
+	return err
 }
 
 //getGeoCoordFromZip returns the lat long from zipcode
 func getGeoCoordFromZip(zipcode int) (geoCoord, error) {
-
-	loadZipCodesIfNeeded()
-
-	zipGeoCoord := zipcodeMap[zipcode]
+	zipGeoCoord, ok := zipcodeMap[zipcode]
+	if !ok {
+		return geoCoord{}, errors.New("latitude, longitude not found for zipcode")
+	}
 	if zipGeoCoord.lat == 0 && zipGeoCoord.long == 0 {
 		return *zipGeoCoord, errors.New("latitude, longitude not found for zipcode")
 	}
 	return *zipGeoCoord, nil
 }
 
-func loadZipcodeMap() error {
+//GetZipcodePoint gives the centroid of a five digit zipcode from the zipcode map read at Init,
+//false when the map does not have it
+func (lcaRepo LcaRepo) GetZipcodePoint(zipcode string) (domain.GeoPoint, bool) {
+	zipcodeKey, err := strconv.Atoi("1" + fmt.Sprintf("%05s", strings.TrimSpace(zipcode)))
+	if err != nil {
+		return domain.GeoPoint{}, false
+	}
+	coord, ok := zipcodeMap[zipcodeKey]
+	if !ok {
+		return domain.GeoPoint{}, false
+	}
+	return domain.GeoPoint{Lat: coord.lat, Long: coord.long}, true
+}
+
+func loadZipcodeMap() (map[int]*geoCoord, error) {
 	f, err := os.Open(zipcodemapFileName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer f.Close()
 
 	// Read File into a Variable
 	lines, err := csv.NewReader(f).ReadAll()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	zipcodeMap = make(map[int]*geoCoord)
+	coords := make(map[int]*geoCoord)
 	// Loop through lines & turn into object
 	for _, line := range lines {
 
 		lat, err := strconv.ParseFloat(strings.TrimSpace(line[1]), 64)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		long, err := strconv.ParseFloat(strings.TrimSpace(line[2]), 64)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		iZipcode, _ := strconv.Atoi("1" + fmt.Sprintf("%05s", strings.TrimSpace(line[0])))
 
-		zipcodeMap[iZipcode] = &geoCoord{lat: lat, long: long}
+		coords[iZipcode] = &geoCoord{lat: lat, long: long}
 	}
-	return nil
+	return coords, nil
 }
 
 // haversin(θ) function
@@ -1,7 +1,12 @@
 package store
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	domain "github.com/kk3399/empnearme/domain"
 	log "github.com/kk3399/empnearme/log"
@@ -10,15 +15,146 @@ import (
 const testingdbFileName = "C:\\Users\\kdamarla\\go\\src\\github.com\\kdamarla\\empnearme\\data.db"
 
 func BenchmarkGet(b *testing.B) {
-	lcaRepo := Init(log.Writer{})
+	lcaRepo := Init(log.Writer{}, DefaultManifest())
 	//d, _ := time.Parse("20060102", "20180101")
 	searchCriteria := domain.SearchCriteria{Radius: 5, Zipcode: "60523", PayMin: 150000}
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
 		k, _ := lcaRepo.Get(searchCriteria)
-		if len(k) == 0 {
+		if k.Total() == 0 {
 			b.Errorf("got %d; want something", 0)
 		}
 	}
 
 }
+
+func newTestRepo(lcas ...domain.Lca) LcaRepo {
+	lcaRepo := LcaRepo{log: log.Writer{}}
+	lcaRepo.store = store{
+		Cases:          make(map[string]domain.Lca),
+		EmployerCases:  make(map[string][]string),
+		ZipcodeCases:   make(map[int][]string),
+		ZipcodesNearBy: make(map[int][]int),
+		StateCases:     make(map[string][]string),
+	}
+	//fixtures are certified unless they say otherwise
+	for _, lca := range lcas {
+		if len(lca.Case_status) == 0 {
+			lca.Case_status = domain.StatusCertified
+		}
+		lcaRepo.add(lca)
+	}
+	lcaRepo.employerIDs = employerIDs(lcaRepo.store.EmployerCases)
+	lcaRepo.titleCounts = titleCounts(lcaRepo.store.Cases)
+	return lcaRepo
+}
+
+func collect(it domain.LcaIterator) []domain.Lca {
+	var lcas []domain.Lca
+	for it.Next() {
+		lcas = append(lcas, it.Lca())
+	}
+	return lcas
+}
+
+func query(t *testing.T, q string) domain.Filter {
+	filter, err := domain.ParseQuery(q, "")
+	if err != nil {
+		t.Fatalf("%s: %v", q, err)
+	}
+	return filter
+}
+
+func TestGetJobTitles(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Job_title: "DATA ENGINEER"},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "160523", Job_title: "DATA ENGINEER"},
+		domain.Lca{Case_number: "3", Employer_name: "ACME", Employer_zip: "194105", Job_title: "DATA SCIENTIST"},
+		domain.Lca{Case_number: "4", Employer_name: "INITECH", Employer_zip: "160523", Job_title: "DATA ANALYST"},
+	)
+
+	titles := lcaRepo.GetJobTitles("DATA", "", "")
+	if len(titles) != 3 || titles[0].Title != "DATA ENGINEER" || titles[0].Count != 2 {
+		t.Errorf("got %v; want DATA ENGINEER ranked first with 2 cases", titles)
+	}
+
+	//unscoped lookups read the counts kept since the repo was built
+	lcaRepo.store.Cases["5"] = domain.Lca{Case_number: "5", Job_title: "DATA ENGINEER"}
+	if titles := lcaRepo.GetJobTitles("DATA", "", ""); titles[0].Count != 2 {
+		t.Errorf("got %v; want the kept count of 2, not a walk of every case", titles)
+	}
+	delete(lcaRepo.store.Cases, "5")
+
+	titles = lcaRepo.GetJobTitles("DATA", "ACME", "94105")
+	if len(titles) != 1 || titles[0].Title != "DATA SCIENTIST" {
+		t.Errorf("got %v; want only DATA SCIENTIST", titles)
+	}
+
+	titles = lcaRepo.GetJobTitles("DATA", "", "60523")
+	if len(titles) != 2 {
+		t.Errorf("got %d titles; want 2", len(titles))
+	}
+}
+
+func TestGetZipcodePoint(t *testing.T) {
+	defer func(coords map[int]*geoCoord) { zipcodeMap = coords }(zipcodeMap)
+	zipcodeMap = map[int]*geoCoord{107302: {lat: 40.72, long: -74.04}}
+
+	lcaRepo := newTestRepo()
+	if point, ok := lcaRepo.GetZipcodePoint("07302"); !ok || point != (domain.GeoPoint{Lat: 40.72, Long: -74.04}) {
+		t.Errorf("got %v, %v; want the centroid of 07302", point, ok)
+	}
+	if point, ok := lcaRepo.GetZipcodePoint("99999"); ok {
+		t.Errorf("got %v; want no centroid for an unknown zipcode", point)
+	}
+}
+
+func TestLcaSourceDolHeaders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "LCA_Disclosure_Data_FY2020.csv")
+	ioutil.WriteFile(name, []byte("CASE_NUMBER,CASE_STATUS,RECEIVED_DATE,EMPLOYER_NAME,EMPLOYER_POSTAL_CODE,FULL_TIME_POSITION,WAGE_RATE_OF_PAY_FROM,WAGE_UNIT_OF_PAY,H_1B_DEPENDENT,VISA_CLASS\n"+
+		"I-1,Certified,2020-01-15,Acme Inc,07302-1234,Yes,60,Hour,No,H-1B\n"+
+		"I-2,Certified,1/15/2020,Acme Inc,07302,Yes,,Year,No,E-3 Australian\n"), 0644)
+
+	var cases []domain.Lca
+	err = lcaSource{path: name, year: 2020, log: log.Writer{}}.Load(func(record interface{}) {
+		cases = append(cases, record.(domain.Lca))
+	})
+	if err != nil || len(cases) != 2 {
+		t.Fatalf("got %v, %v; want both cases", cases, err)
+	}
+	lca := cases[0]
+	if lca.Year != 2020 || lca.Case_status != domain.StatusCertified || lca.Employer_name != "ACME INC" || lca.Employer_zip != "107302" ||
+		lca.Full_time != "Y" || lca.H1b_dependent != "N" || lca.Pay != 124800 || lca.Submit_date.Month() != time.January {
+		t.Errorf("got %+v; want DOL columns read", lca)
+	}
+	if cases[1].Submit_date != lca.Submit_date || cases[1].Visa_class != domain.VisaE3 {
+		t.Errorf("got %+v; want either date layout and the visa class read", cases[1])
+	}
+}
+
+func TestLcaSourceShortRows(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "lca.csv")
+	ioutil.WriteFile(name, []byte("2020,I-1,CERTIFIED,1/15/2020,1/22/2020,,,ACME,1 MAIN ST,CHICAGO,IL,60601,DATA ENGINEER,15-1252,SOFTWARE DEVELOPERS,541511,1,Y,120000,Year,II,N,N,CHICAGO,IL,60601\n"+
+		"2020,I-2,CERTIFIED,1/15/2020\n"+
+		"2020,I-3,CERTIFIED,1/15/2020,1/22/2020,,,ACME,1 MAIN ST,CHICAGO,IL,60601,DATA SCIENTIST,15-2051,DATA SCIENTISTS,541511,1,Y,130000,Year,II,N,N,CHICAGO,IL,60601,E-3\n"), 0644)
+
+	var cases []string
+	err = lcaSource{path: name, year: 2020, log: log.Writer{}}.Load(func(record interface{}) {
+		cases = append(cases, record.(domain.Lca).Case_number)
+	})
+	if err != nil || strings.Join(cases, ",") != "I-1,I-3" {
+		t.Errorf("got %v, %v; want the short rows skipped", cases, err)
+	}
+}
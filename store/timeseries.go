@@ -0,0 +1,84 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+//caseDate of a case for a date sort key
+func caseDate(lca domain.Lca, dateField string) time.Time {
+	switch dateField {
+	case domain.SortByDecisionDate:
+		return lca.Decision_date
+	case domain.SortByStartDate:
+		return lca.Start_date
+	}
+	return lca.Submit_date
+}
+
+//bucketStart is the first day of the month or quarter t falls in
+func bucketStart(t time.Time, interval string) time.Time {
+	month := t.Month()
+	if interval == domain.IntervalQuarter {
+		month = month - (month-1)%3
+	}
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func bucketPeriod(start time.Time, interval string) string {
+	if interval == domain.IntervalQuarter {
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	}
+	return start.Format("2006-01")
+}
+
+//GetTimeSeries counts matching cases per month or quarter of a date field. Every period
+//between the first and the last is listed, so quiet months show up as zero
+func (lcaRepo LcaRepo) GetTimeSeries(searchCriteria domain.SearchCriteria, interval string, dateField string) ([]domain.TimeBucket, error) {
+	months := 1
+	if interval == domain.IntervalQuarter {
+		months = 3
+	}
+
+	buckets := make(map[time.Time]*domain.TimeBucket)
+	var first, last time.Time
+
+	for _, casenum := range lcaRepo.search(searchCriteria) {
+		lca := lcaRepo.store.Cases[casenum]
+		date := caseDate(lca, dateField)
+		if date.IsZero() {
+			continue
+		}
+
+		start := bucketStart(date, interval)
+		bucket, ok := buckets[start]
+		if !ok {
+			bucket = &domain.TimeBucket{Period: bucketPeriod(start, interval), Start: start}
+			buckets[start] = bucket
+		}
+		bucket.Filings++
+		bucket.Workers += lca.Total_workers
+
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+
+	series := []domain.TimeBucket{}
+	if first.IsZero() {
+		return series, nil
+	}
+	for start := first; !start.After(last); start = start.AddDate(0, months, 0) {
+		if bucket, ok := buckets[start]; ok {
+			series = append(series, *bucket)
+		} else {
+			series = append(series, domain.TimeBucket{Period: bucketPeriod(start, interval), Start: start})
+		}
+	}
+	return series, nil
+}
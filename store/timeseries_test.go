@@ -0,0 +1,26 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	domain "github.com/kk3399/empnearme/domain"
+)
+
+func TestGetTimeSeries(t *testing.T) {
+	lcaRepo := newTestRepo(
+		domain.Lca{Case_number: "1", Employer_name: "ACME", Employer_zip: "160523", Total_workers: 2, Submit_date: time.Date(2019, 1, 15, 0, 0, 0, 0, time.UTC)},
+		domain.Lca{Case_number: "2", Employer_name: "ACME", Employer_zip: "160523", Total_workers: 1, Submit_date: time.Date(2019, 1, 20, 0, 0, 0, 0, time.UTC)},
+		domain.Lca{Case_number: "3", Employer_name: "ACME", Employer_zip: "160523", Total_workers: 5, Submit_date: time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)},
+	)
+
+	series, _ := lcaRepo.GetTimeSeries(domain.SearchCriteria{Employer: "ACME"}, domain.IntervalMonth, domain.SortBySubmitDate)
+	if len(series) != 4 || series[0].Period != "2019-01" || series[0].Filings != 2 || series[0].Workers != 3 || series[1].Filings != 0 {
+		t.Errorf("got %+v; want January to April with empty months in between", series)
+	}
+
+	series, _ = lcaRepo.GetTimeSeries(domain.SearchCriteria{Employer: "ACME"}, domain.IntervalQuarter, domain.SortBySubmitDate)
+	if len(series) != 2 || series[1].Period != "2019-Q2" || series[1].Workers != 5 {
+		t.Errorf("got %+v; want Q1 and Q2", series)
+	}
+}
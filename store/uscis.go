@@ -0,0 +1,143 @@
+package store
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	domain "github.com/kk3399/empnearme/domain"
+	log "github.com/kk3399/empnearme/log"
+)
+
+//UscisRepo - USCIS H-1B Employer Data Hub rows, by normalized employer name
+type UscisRepo struct {
+	names map[string][]uscisRow
+	log   log.Writer
+}
+
+//uscisRow is one employer's petition decisions of one fiscal year, name is the normalized
+//employer name and taxID holds the last four digits of the employer's FEIN
+type uscisRow struct {
+	name  string
+	year  domain.PetitionYear
+	taxID string
+}
+
+//uscisColumns are the Data Hub headers each field is read from, the hub renamed them in later files
+var uscisColumns = map[string][]string{
+	"year":                 {"FISCAL YEAR"},
+	"employer":             {"EMPLOYER", "EMPLOYER (PETITIONER) NAME"},
+	"tax_id":               {"TAX ID"},
+	"initial_approvals":    {"INITIAL APPROVAL", "INITIAL APPROVALS", "NEW EMPLOYMENT APPROVAL"},
+	"initial_denials":      {"INITIAL DENIAL", "INITIAL DENIALS", "NEW EMPLOYMENT DENIAL"},
+	"continuing_approvals": {"CONTINUING APPROVAL", "CONTINUING APPROVALS", "CONTINUATION APPROVAL"},
+	"continuing_denials":   {"CONTINUING DENIAL", "CONTINUING DENIALS", "CONTINUATION DENIAL"},
+}
+
+//InitUscis loads the Data Hub files of the manifest
+func InitUscis(log log.Writer, manifest Manifest) UscisRepo {
+	uscisRepo := UscisRepo{names: make(map[string][]uscisRow), log: log}
+	manifest.load(SourceUscis, log, func(record interface{}) {
+		row := record.(uscisRow)
+		uscisRepo.names[row.name] = append(uscisRepo.names[row.name], row)
+	})
+	return uscisRepo
+}
+
+//uscisSource reads a Data Hub file of one fiscal year
+type uscisSource struct {
+	path string
+	year int
+}
+
+func (source uscisSource) Load(add func(record interface{})) error {
+	rows, closer, err := openRows(source.path)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	return source.read(rows, add)
+}
+
+//read a Data Hub file by its header, rows without a fiscal year are of the file's year
+func (source uscisSource) read(rows rowReader, add func(record interface{})) error {
+	first, err := rows.Read()
+	if err != nil {
+		return err
+	}
+	header := newColumns(first)
+
+	for {
+		line, err := rows.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		field := func(name string) string {
+			return header.field(line, uscisColumns[name])
+		}
+		count := func(name string) int {
+			n, _ := strconv.Atoi(strings.Replace(field(name), ",", "", -1))
+			return n
+		}
+
+		name := domain.NormalizeEmployerName(field("employer"))
+		if len(name) == 0 {
+			continue
+		}
+
+		row := uscisRow{name: name, taxID: domain.NormalizeFein(field("tax_id"))}
+		row.year.Year, err = strconv.Atoi(field("year"))
+		if err != nil {
+			row.year.Year = source.year
+		}
+		row.year.InitialApprovals = count("initial_approvals")
+		row.year.InitialDenials = count("initial_denials")
+		row.year.ContinuingApprovals = count("continuing_approvals")
+		row.year.ContinuingDenials = count("continuing_denials")
+
+		add(row)
+	}
+}
+
+//GetEmployerPetitions sums up the Data Hub rows of an LCA employer matched by normalized name.
+//Where both the row's tax id and the employer's FEIN are known the FEIN has to end in the tax id
+func (uscisRepo UscisRepo) GetEmployerPetitions(name string, fein string) domain.Petitions {
+	fein = domain.NormalizeFein(fein)
+
+	var petitions domain.Petitions
+	years := make(map[int]*domain.PetitionYear)
+
+	for _, row := range uscisRepo.names[domain.NormalizeEmployerName(name)] {
+		if len(fein) > 0 && len(row.taxID) > 0 && !strings.HasSuffix(fein, row.taxID) {
+			continue
+		}
+
+		year, ok := years[row.year.Year]
+		if !ok {
+			year = &domain.PetitionYear{Year: row.year.Year}
+			years[row.year.Year] = year
+		}
+		year.InitialApprovals += row.year.InitialApprovals
+		year.InitialDenials += row.year.InitialDenials
+		year.ContinuingApprovals += row.year.ContinuingApprovals
+		year.ContinuingDenials += row.year.ContinuingDenials
+
+		petitions.InitialApprovals += row.year.InitialApprovals
+		petitions.InitialDenials += row.year.InitialDenials
+		petitions.ContinuingApprovals += row.year.ContinuingApprovals
+		petitions.ContinuingDenials += row.year.ContinuingDenials
+	}
+
+	for _, year := range years {
+		petitions.Years = append(petitions.Years, *year)
+	}
+	sort.Slice(petitions.Years, func(i, j int) bool {
+		return petitions.Years[i].Year < petitions.Years[j].Year
+	})
+	return petitions
+}
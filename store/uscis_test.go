@@ -0,0 +1,33 @@
+package store
+
+import (
+	"strings"
+	"testing"
+
+	log "github.com/kk3399/empnearme/log"
+)
+
+func TestUscisEmployerPetitions(t *testing.T) {
+	uscisRepo := UscisRepo{names: make(map[string][]uscisRow), log: log.Writer{}}
+	add := func(record interface{}) {
+		row := record.(uscisRow)
+		uscisRepo.names[row.name] = append(uscisRepo.names[row.name], row)
+	}
+	csv := `Fiscal Year   ,Employer,Initial Approval,Initial Denial,Continuing Approval,Continuing Denial,NAICS,Tax ID,State,City,ZIP
+2019,"ACME, INC.",10,2,"1,200",3,54,6789,IL,WESTMONT,60559
+2020,ACME INC,5,1,20,0,54,6789,IL,WESTMONT,60559
+2020,ACME INC,7,0,0,0,54,1111,TX,AUSTIN,78701
+`
+	if err := (uscisSource{year: 2020}).read(csvRows(strings.NewReader(csv)), add); err != nil {
+		t.Fatalf("got %v; want no error", err)
+	}
+
+	petitions := uscisRepo.GetEmployerPetitions("Acme Inc", "12-3456789")
+	if petitions.InitialApprovals != 15 || petitions.InitialDenials != 3 || petitions.ContinuingApprovals != 1220 || len(petitions.Years) != 2 {
+		t.Errorf("got %+v; want the rows whose tax id ends the FEIN", petitions)
+	}
+
+	if petitions := uscisRepo.GetEmployerPetitions("ACME INC", ""); petitions.InitialApprovals != 22 || petitions.Years[1].InitialApprovals != 12 {
+		t.Errorf("got %+v; want every ACME row without a FEIN to tell them apart", petitions)
+	}
+}